@@ -0,0 +1,236 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ChannelOrder describes the order in which the colour channels appear in a
+// hexadecimal colour string (such as "#ff000080") or an integer colour value
+// (such as "0xff0000"). Each letter names one channel: R (red), G (green), B
+// (blue) and A (alpha). The zero value is treated as ChannelOrderRGBA which
+// is the conventional (CSS) ordering.
+type ChannelOrder string
+
+// These are the supported channel orders
+const (
+	// ChannelOrderRGBA is the CSS ordering: #RRGGBB or #RRGGBBAA
+	ChannelOrderRGBA ChannelOrder = "RGBA"
+	// ChannelOrderARGB is the Android ordering: #AARRGGBB
+	ChannelOrderARGB ChannelOrder = "ARGB"
+	// ChannelOrderBGRA is the little-endian ARGB ordering: #BBGGRRAA
+	ChannelOrderBGRA ChannelOrder = "BGRA"
+	// ChannelOrderABGR is the Google Earth KML ordering: aabbggrr
+	ChannelOrderABGR ChannelOrder = "ABGR"
+	// ChannelOrderRGB is the ordering with no alpha channel: #RRGGBB
+	ChannelOrderRGB ChannelOrder = "RGB"
+	// ChannelOrderBGR is the OpenCV ordering and that of the Win32
+	// COLORREF: 0x00BBGGRR
+	ChannelOrderBGR ChannelOrder = "BGR"
+)
+
+// channelOrders maps the lower-case names of the supported channel orders
+// (and their aliases) to the ChannelOrder. These names are used as prefixes
+// to give an explicit channel order for a single colour value.
+var channelOrders = map[string]ChannelOrder{
+	"rgba": ChannelOrderRGBA,
+	"argb": ChannelOrderARGB,
+	"bgra": ChannelOrderBGRA,
+	"abgr": ChannelOrderABGR,
+	"bgr":  ChannelOrderBGR,
+	"kml":  ChannelOrderABGR,
+}
+
+// maxRGBIntDigits is the largest number of hexadecimal digits in an integer
+// colour that is taken as RGB (rather than RGBA) with the RGBA ChannelOrder
+const maxRGBIntDigits = 6
+
+var (
+	hexColourRE = regexp.MustCompile(`^#[[:xdigit:]]+$`)
+	intColourRE = regexp.MustCompile(`^0[xX][[:xdigit:]]+$`)
+)
+
+// channels returns the channel order, the zero value is mapped to the
+// default ordering.
+func (co ChannelOrder) channels() string {
+	if co == "" {
+		return string(ChannelOrderRGBA)
+	}
+
+	return string(co)
+}
+
+// String returns the channel order
+func (co ChannelOrder) String() string {
+	return co.channels()
+}
+
+// Check returns a non-nil error if the ChannelOrder is not one of the
+// supported values.
+func (co ChannelOrder) Check() error {
+	switch ChannelOrder(co.channels()) {
+	case ChannelOrderRGBA, ChannelOrderARGB, ChannelOrderBGRA,
+		ChannelOrderABGR, ChannelOrderRGB, ChannelOrderBGR:
+		return nil
+	}
+
+	return fmt.Errorf("%q is not a valid ChannelOrder", string(co))
+}
+
+// hasAlpha returns true if the channel order includes an alpha channel
+func (co ChannelOrder) hasAlpha() bool {
+	return strings.Contains(co.channels(), "A")
+}
+
+// setChannels sets the channels of the colour from the values, taken in the
+// given channel order. The alpha channel defaults to fully opaque.
+func setChannels(channels string, vals []uint8) color.RGBA { //nolint:misspell
+	c := color.RGBA{A: 0xff} //nolint:misspell
+
+	for i, ch := range channels {
+		switch ch {
+		case 'R':
+			c.R = vals[i]
+		case 'G':
+			c.G = vals[i]
+		case 'B':
+			c.B = vals[i]
+		case 'A':
+			c.A = vals[i]
+		}
+	}
+
+	return c
+}
+
+// ParseHex parses a string of hexadecimal digits (optionally preceded by a
+// hash ("#")) taking the channels in the ChannelOrder. The string may have
+// one or two digits per channel; single digits are doubled so that "f" is
+// treated as "ff". If the channel order includes an alpha channel it may be
+// omitted in which case the colour is fully opaque.
+func (co ChannelOrder) ParseHex(s string) (color.RGBA, error) { //nolint:misspell
+	digits := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	channels := co.channels()
+
+	if co.hasAlpha() && len(digits)%len(channels) != 0 {
+		channels = strings.Replace(channels, "A", "", 1)
+	}
+
+	digitsPerChannel := 0
+
+	switch len(digits) {
+	case len(channels):
+		digitsPerChannel = 1
+	case 2 * len(channels): //nolint:mnd
+		digitsPerChannel = 2 //nolint:mnd
+	default:
+		return color.RGBA{}, //nolint:misspell
+			fmt.Errorf("the hex colour (%q) is badly formed:"+
+				" %d digits found, %s",
+				s, len(digits), co.allowedDigitCounts())
+	}
+
+	vals := make([]uint8, 0, len(channels))
+
+	for i := range len(channels) {
+		xd := digits[i*digitsPerChannel : (i+1)*digitsPerChannel]
+		if digitsPerChannel == 1 {
+			xd += xd
+		}
+
+		v, err := strconv.ParseUint(xd, 16, 8)
+		if err != nil {
+			return color.RGBA{}, //nolint:misspell
+				fmt.Errorf("the hex colour (%q) is badly formed:"+
+					" digit %d(%s) cannot be converted to a number",
+					s, i+1, xd)
+		}
+
+		vals = append(vals, uint8(v))
+	}
+
+	return setChannels(channels, vals), nil
+}
+
+// allowedDigitCounts returns a string describing the allowed number of
+// hexadecimal digits for the channel order
+func (co ChannelOrder) allowedDigitCounts() string {
+	if co.hasAlpha() {
+		return "3, 4, 6 or 8 expected (order: " + co.channels() + ")"
+	}
+
+	return "3 or 6 expected (order: " + co.channels() + ")"
+}
+
+// ParseInt parses an integer value given in hexadecimal with a leading "0x"
+// and takes the channels from the most significant byte downwards in the
+// ChannelOrder. Unlike ParseHex, leading zeros are not significant so, for
+// instance, with a ChannelOrder of ChannelOrderARGB the value "0xff0000"
+// gives a fully transparent red. With ChannelOrderRGBA (or the zero value)
+// an integer of up to 6 hexadecimal digits is taken as a conventional
+// 0xRRGGBB value and the colour is fully opaque.
+func (co ChannelOrder) ParseInt(s string) (color.RGBA, error) { //nolint:misspell
+	s = strings.TrimSpace(s)
+	channels := co.channels()
+
+	if channels == string(ChannelOrderRGBA) &&
+		len(s) <= len("0x")+maxRGBIntDigits {
+		channels = string(ChannelOrderRGB)
+	}
+
+	bits := 8 * len(channels) //nolint:mnd
+
+	v, err := strconv.ParseUint(s, 0, bits)
+	if err != nil {
+		return color.RGBA{}, //nolint:misspell
+			fmt.Errorf("the integer colour (%q) is invalid"+
+				" for the channel order %s: %w",
+				s, channels, err)
+	}
+
+	vals := make([]uint8, len(channels))
+	for i := range vals {
+		shift := 8 * (len(channels) - 1 - i) //nolint:mnd
+		vals[i] = uint8(v >> shift)          //nolint:gosec
+	}
+
+	return setChannels(channels, vals), nil
+}
+
+// Hex returns the colour as a hash ("#") followed by two hexadecimal digits
+// per channel in the ChannelOrder.
+func (co ChannelOrder) Hex(c color.RGBA) string { //nolint:misspell
+	var b strings.Builder
+
+	b.WriteString("#")
+
+	for _, ch := range co.channels() {
+		switch ch {
+		case 'R':
+			fmt.Fprintf(&b, "%02x", c.R)
+		case 'G':
+			fmt.Fprintf(&b, "%02x", c.G)
+		case 'B':
+			fmt.Fprintf(&b, "%02x", c.B)
+		case 'A':
+			fmt.Fprintf(&b, "%02x", c.A)
+		}
+	}
+
+	return b.String()
+}
+
+// parseOrderedColour parses the value as either a hex colour or an integer
+// colour using the given channel order.
+func parseOrderedColour(co ChannelOrder, s string) (color.RGBA, error) { //nolint:misspell
+	s = strings.TrimSpace(s)
+
+	if intColourRE.MatchString(s) {
+		return co.ParseInt(s)
+	}
+
+	return co.ParseHex(s)
+}
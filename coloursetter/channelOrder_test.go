@@ -0,0 +1,139 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colourtesthelper"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestChannelOrderParse(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		co     ChannelOrder
+		v      string
+		expVal color.RGBA //nolint:misspell
+	}{
+		{
+			ID:     testhelper.MkID("default order, 6 digits"),
+			v:      "#102030",
+			expVal: color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("default order, 4 digits"),
+			v:      "#1238",
+			expVal: color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0x88}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("ARGB, 8 digits"),
+			co:     ChannelOrderARGB,
+			v:      "#80102030",
+			expVal: color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0x80}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("ARGB, 6 digits"),
+			co:     ChannelOrderARGB,
+			v:      "#102030",
+			expVal: color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("ABGR, 8 digits, no hash"),
+			co:     ChannelOrderABGR,
+			v:      "80302010",
+			expVal: color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0x80}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("ARGB, integer"),
+			co:     ChannelOrderARGB,
+			v:      "0xff0000",
+			expVal: color.RGBA{R: 0xff, G: 0, B: 0, A: 0}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("default order, 6 digit integer"),
+			v:      "0xff0000",
+			expVal: color.RGBA{R: 0xff, G: 0, B: 0, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("default order, short integer"),
+			v:      "0xff",
+			expVal: color.RGBA{R: 0, G: 0, B: 0xff, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("default order, 8 digit integer"),
+			v:      "0x10203080",
+			expVal: color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0x80}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("RGBA, 6 digit integer"),
+			co:     ChannelOrderRGBA,
+			v:      "0xff0000",
+			expVal: color.RGBA{R: 0xff, G: 0, B: 0, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("RGBA, 8 digit integer"),
+			co:     ChannelOrderRGBA,
+			v:      "0x00ff0000",
+			expVal: color.RGBA{R: 0, G: 0xff, B: 0, A: 0}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("BGR, integer (COLORREF)"),
+			co:     ChannelOrderBGR,
+			v:      "0x00302010",
+			expVal: color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID: testhelper.MkID("BGR, integer too big"),
+			ExpErr: testhelper.MkExpErr(
+				`the integer colour ("0x1000000") is invalid`+
+					` for the channel order BGR:`,
+				`value out of range`),
+			co: ChannelOrderBGR,
+			v:  "0x1000000",
+		},
+		{
+			ID: testhelper.MkID("RGB, bad digit count"),
+			ExpErr: testhelper.MkExpErr(
+				`the hex colour ("#1234") is badly formed:` +
+					` 4 digits found, 3 or 6 expected (order: RGB)`),
+			co: ChannelOrderRGB,
+			v:  "#1234",
+		},
+		{
+			ID: testhelper.MkID("bad digit"),
+			ExpErr: testhelper.MkExpErr(
+				`the hex colour ("12345x") is badly formed:` +
+					` digit 3(5x) cannot be converted to a number`),
+			v: "12345x",
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := parseOrderedColour(tc.co, tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			colourtesthelper.DiffRGBA(t, tc.IDStr(), "colour", c, tc.expVal)
+		}
+	}
+}
+
+func TestChannelOrderHex(t *testing.T) {
+	c := color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0x80} //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		co     ChannelOrder
+		expVal string
+	}{
+		{ID: testhelper.MkID("default"), expVal: "#10203080"},
+		{ID: testhelper.MkID("ARGB"), co: ChannelOrderARGB, expVal: "#80102030"},
+		{ID: testhelper.MkID("ABGR"), co: ChannelOrderABGR, expVal: "#80302010"},
+		{ID: testhelper.MkID("BGR"), co: ChannelOrderBGR, expVal: "#302010"},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffString(t, tc.IDStr(), "Hex", tc.co.Hex(c), tc.expVal)
+	}
+}
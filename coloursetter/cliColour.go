@@ -0,0 +1,278 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"golang.org/x/term"
+)
+
+// ColourMode says whether a program should use colour in its output
+type ColourMode string
+
+// These are the supported colour modes
+const (
+	// ColourModeAuto uses colour if the environment allows it and the
+	// output is a terminal (see CLIColour.Enabled)
+	ColourModeAuto ColourMode = "auto"
+	// ColourModeAlways always uses colour
+	ColourModeAlways ColourMode = "always"
+	// ColourModeNever never uses colour
+	ColourModeNever ColourMode = "never"
+)
+
+// These are the names of the parameters and the parameter group added by
+// AddCLIColourParams
+const (
+	CLIColourGroupName = "pkg.coloursetter"
+
+	CLIColourParamName         = "colour"
+	CLIColourFamiliesParamName = "colour-families"
+	CLIListColoursParamName    = "list-colours"
+	CLIThemeParamName          = "theme"
+)
+
+// These are the environment variables consulted in ColourModeAuto
+const (
+	EnvNoColor       = "NO_COLOR"
+	EnvForceColor    = "FORCE_COLOR"
+	EnvCLIColor      = "CLICOLOR"
+	EnvCLIColorForce = "CLICOLOR_FORCE"
+	EnvTerm          = "TERM"
+)
+
+// CLIColour holds the settings of the standard colour parameters added by
+// AddCLIColourParams. The program should query it once the parameters have
+// been parsed.
+type CLIColour struct {
+	// Mode is the colour mode given by the colour parameter
+	Mode ColourMode
+	// Families is the list of colour families given by the colour-families
+	// parameter. If it is empty the standard families are used.
+	Families colour.Families
+	// Scope shares the Families. It should be given as the Scope of any
+	// RGB, RGBPair or NamedColour setters whose colour names are to be
+	// interpreted using the colour-families parameter. Its final check is
+	// added to the param set by AddCLIColourParams.
+	Scope *FamilyScope
+	// ListColours is set if the list-colours parameter was given. The
+	// parameter has no effect by itself; the program should check this
+	// once the parameters have been parsed and, if it is set, write the
+	// colours (see WriteColourList) and exit.
+	ListColours bool
+	// Theme is the theme chosen by the theme parameter
+	Theme string
+	// Out is the file whose output is to be coloured. In ColourModeAuto
+	// colour is only used if it is a terminal. It is os.Stdout by default.
+	Out *os.File
+
+	lookupEnv  func(string) (string, bool)
+	isTerminal func(*os.File) bool
+}
+
+// AddCLIColourParams adds a group of parameters to the param set for
+// choosing whether and how a program uses colour: colour (whether to use
+// colour: auto, always or never), colour-families (the families used to
+// interpret colour names), list-colours (to show the available colours)
+// and, if any themes are given, theme (to choose one of the themes). It
+// returns the CLIColour holding the parameter values. The final check of
+// its Scope is added to the param set so this should be called before any
+// other final checks which use the colours are added. It panics if themes
+// are given and the default theme is not one of them.
+func AddCLIColourParams(ps *param.PSet,
+	themes psetter.AllowedVals[string], dfltTheme string,
+) *CLIColour {
+	c := &CLIColour{
+		Mode:       ColourModeAuto,
+		Theme:      dfltTheme,
+		Out:        os.Stdout,
+		lookupEnv:  os.LookupEnv,
+		isTerminal: isTerminal,
+	}
+	c.Scope = NewFamilyScope(&c.Families)
+
+	if len(themes) > 0 {
+		if _, ok := themes[dfltTheme]; !ok {
+			panic(fmt.Sprintf("coloursetter.AddCLIColourParams:"+
+				" the default theme (%q) is not one of the themes",
+				dfltTheme))
+		}
+	}
+
+	ps.AddGroup(CLIColourGroupName,
+		"These are the parameters controlling the use of colour."+
+			" By default colour is used only if the output is to"+
+			" a terminal and the environment allows it.")
+
+	ps.Add(CLIColourParamName,
+		psetter.Enum[ColourMode]{
+			Value: &c.Mode,
+			AllowedVals: psetter.AllowedVals[ColourMode]{
+				ColourModeAuto: "use colour if the output is to a terminal" +
+					" unless the environment says otherwise (see below)",
+				ColourModeAlways: "always use colour",
+				ColourModeNever:  "never use colour",
+			},
+			Aliases: psetter.Aliases[ColourMode]{
+				"yes":    {ColourModeAlways},
+				"force":  {ColourModeAlways},
+				"no":     {ColourModeNever},
+				"none":   {ColourModeNever},
+				"if-tty": {ColourModeAuto},
+			},
+		},
+		"whether colour should be used in the output."+
+			" In "+string(ColourModeAuto)+" mode colour is not used if"+
+			" the "+EnvNoColor+" environment variable is set"+
+			" to a non-empty value or if "+EnvForceColor+" is set to 0;"+
+			" otherwise it is used if "+EnvForceColor+
+			" or "+EnvCLIColorForce+" is set to other than 0;"+
+			" otherwise it is not used if "+EnvTerm+" is dumb"+
+			" or "+EnvCLIColor+" is 0;"+
+			" otherwise it is used only if the output is to a terminal",
+		param.AltNames("color"),
+		param.GroupName(CLIColourGroupName),
+	)
+
+	ps.Add(CLIColourFamiliesParamName,
+		Families{Value: &c.Families},
+		"the colour families used to find colours by name."+
+			" If no families are given the standard families are used",
+		param.AltNames("color-families"),
+		param.GroupName(CLIColourGroupName),
+	)
+	c.Scope.AddFinalCheck(ps)
+
+	ps.Add(CLIListColoursParamName,
+		psetter.Bool{Value: &c.ListColours},
+		"ask for the names of the colours in the colour families"+
+			" to be shown",
+		param.AltNames("list-colors"),
+		param.GroupName(CLIColourGroupName),
+		param.Attrs(param.CommandLineOnly),
+	)
+
+	if len(themes) > 0 {
+		ps.Add(CLIThemeParamName,
+			psetter.Enum[string]{Value: &c.Theme, AllowedVals: themes},
+			"the colour theme to use",
+			param.GroupName(CLIColourGroupName),
+		)
+	}
+
+	return c
+}
+
+// isTerminal returns true if the file is a terminal
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+
+	return term.IsTerminal(int(f.Fd())) //nolint:gosec
+}
+
+// envIsSet returns true if the environment variable is set to a non-empty
+// value other than "0"
+func (c *CLIColour) envIsSet(name string) bool {
+	val, ok := c.lookupEnv(name)
+
+	return ok && val != "" && val != "0"
+}
+
+// envIsZero returns true if the environment variable is set to "0"
+func (c *CLIColour) envIsZero(name string) bool {
+	val, ok := c.lookupEnv(name)
+
+	return ok && val == "0"
+}
+
+// Enabled returns true if colour should be used. In ColourModeAlways and
+// ColourModeNever the mode decides. In ColourModeAuto the conventions
+// below are followed, the first that applies deciding:
+//
+//	NO_COLOR set (to any non-empty value)           no colour
+//	FORCE_COLOR=0                                   no colour
+//	FORCE_COLOR or CLICOLOR_FORCE set (not to 0)    colour
+//	TERM=dumb                                       no colour
+//	CLICOLOR=0                                      no colour
+//	otherwise                                       colour if Out is a terminal
+//
+// Note that CLICOLOR_FORCE=0 does not disable colour, it just means that
+// colour is not forced.
+func (c *CLIColour) Enabled() bool {
+	switch c.Mode {
+	case ColourModeAlways:
+		return true
+	case ColourModeNever:
+		return false
+	}
+
+	if val, ok := c.lookupEnv(EnvNoColor); ok && val != "" {
+		return false
+	}
+
+	if c.envIsZero(EnvForceColor) {
+		return false
+	}
+
+	if c.envIsSet(EnvForceColor) || c.envIsSet(EnvCLIColorForce) {
+		return true
+	}
+
+	if val, _ := c.lookupEnv(EnvTerm); val == "dumb" {
+		return false
+	}
+
+	if val, ok := c.lookupEnv(EnvCLIColor); ok && val == "0" {
+		return false
+	}
+
+	return c.isTerminal(c.Out)
+}
+
+// WriteColourList writes the names of the colours in the Families (or in
+// the standard families if the Families is empty), in alphabetical order,
+// one per line, each followed by the colour in hexadecimal. If a name
+// appears in more than one family the colour is taken from the first.
+func (c *CLIColour) WriteColourList(w io.Writer) error {
+	fl := c.Families
+	if len(fl) == 0 {
+		fl = colour.Families{colour.StandardColours}
+	}
+
+	colours := map[string]color.RGBA{} //nolint:misspell
+
+	for _, f := range fl {
+		names, err := f.ColourNames()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if _, ok := colours[name]; ok {
+				continue
+			}
+
+			if colours[name], err = f.Colour(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(colours)) {
+		_, err := fmt.Fprintln(w, name, ChannelOrderRGB.Hex(colours[name]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
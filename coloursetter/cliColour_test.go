@@ -0,0 +1,254 @@
+package coloursetter
+
+import (
+	"errors"
+	"image/color" //nolint:misspell
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestCLIColourEnabled(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		mode     ColourMode
+		env      map[string]string
+		terminal bool
+		expVal   bool
+	}{
+		{
+			ID:       testhelper.MkID("auto, terminal"),
+			mode:     ColourModeAuto,
+			terminal: true,
+			expVal:   true,
+		},
+		{
+			ID:     testhelper.MkID("auto, not a terminal"),
+			mode:   ColourModeAuto,
+			expVal: false,
+		},
+		{
+			ID:       testhelper.MkID("auto, NO_COLOR"),
+			mode:     ColourModeAuto,
+			env:      map[string]string{EnvNoColor: "1", EnvForceColor: "1"},
+			terminal: true,
+			expVal:   false,
+		},
+		{
+			ID:       testhelper.MkID("auto, empty NO_COLOR"),
+			mode:     ColourModeAuto,
+			env:      map[string]string{EnvNoColor: ""},
+			terminal: true,
+			expVal:   true,
+		},
+		{
+			ID:     testhelper.MkID("auto, FORCE_COLOR"),
+			mode:   ColourModeAuto,
+			env:    map[string]string{EnvForceColor: "1", EnvTerm: "dumb"},
+			expVal: true,
+		},
+		{
+			ID:       testhelper.MkID("auto, FORCE_COLOR=0"),
+			mode:     ColourModeAuto,
+			env:      map[string]string{EnvForceColor: "0"},
+			terminal: false,
+			expVal:   false,
+		},
+		{
+			ID:       testhelper.MkID("auto, terminal, FORCE_COLOR=0"),
+			mode:     ColourModeAuto,
+			env:      map[string]string{EnvForceColor: "0"},
+			terminal: true,
+			expVal:   false,
+		},
+		{
+			ID:       testhelper.MkID("auto, terminal, CLICOLOR_FORCE=0"),
+			mode:     ColourModeAuto,
+			env:      map[string]string{EnvCLIColorForce: "0"},
+			terminal: true,
+			expVal:   true,
+		},
+		{
+			ID:       testhelper.MkID("auto, CLICOLOR_FORCE=0"),
+			mode:     ColourModeAuto,
+			env:      map[string]string{EnvCLIColorForce: "0"},
+			terminal: false,
+			expVal:   false,
+		},
+		{
+			ID:     testhelper.MkID("auto, CLICOLOR_FORCE"),
+			mode:   ColourModeAuto,
+			env:    map[string]string{EnvCLIColorForce: "1"},
+			expVal: true,
+		},
+		{
+			ID:       testhelper.MkID("auto, TERM=dumb"),
+			mode:     ColourModeAuto,
+			env:      map[string]string{EnvTerm: "dumb"},
+			terminal: true,
+			expVal:   false,
+		},
+		{
+			ID:       testhelper.MkID("auto, CLICOLOR=0"),
+			mode:     ColourModeAuto,
+			env:      map[string]string{EnvCLIColor: "0"},
+			terminal: true,
+			expVal:   false,
+		},
+		{
+			ID:     testhelper.MkID("always, not a terminal"),
+			mode:   ColourModeAlways,
+			env:    map[string]string{EnvNoColor: "1"},
+			expVal: true,
+		},
+		{
+			ID:       testhelper.MkID("never, terminal"),
+			mode:     ColourModeNever,
+			env:      map[string]string{EnvForceColor: "1"},
+			terminal: true,
+			expVal:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		c := CLIColour{
+			Mode: tc.mode,
+			lookupEnv: func(name string) (string, bool) {
+				val, ok := tc.env[name]
+				return val, ok
+			},
+			isTerminal: func(*os.File) bool { return tc.terminal },
+		}
+
+		testhelper.DiffBool(t, tc.IDStr(), "enabled", c.Enabled(), tc.expVal)
+	}
+}
+
+func TestCLIColourWriteColourList(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		fams     colour.Families
+		expFirst string
+		expLine  string
+	}{
+		{
+			ID:       testhelper.MkID("web"),
+			fams:     colour.Families{colour.WebColours},
+			expFirst: "aqua #00ffff",
+			expLine:  "navy #000080",
+		},
+		{
+			ID:       testhelper.MkID("no families - the standard families"),
+			expFirst: "abbey stone #aba798",
+			expLine:  "lavender #e6e6fa",
+		},
+	}
+
+	for _, tc := range testCases {
+		c := CLIColour{Families: tc.fams}
+
+		var sb strings.Builder
+		if err := c.WriteColourList(&sb); err != nil {
+			t.Log(tc.IDStr())
+			t.Error("unexpected error writing the colours: ", err)
+
+			continue
+		}
+
+		lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+		testhelper.DiffString(t, tc.IDStr(), "first line",
+			lines[0], tc.expFirst)
+
+		if !slices.Contains(lines, tc.expLine) {
+			t.Log(tc.IDStr())
+			t.Errorf("the colour list should contain %q", tc.expLine)
+		}
+
+		if !slices.IsSorted(lines) {
+			t.Log(tc.IDStr())
+			t.Error("the colour list should be sorted")
+		}
+	}
+}
+
+func TestAddCLIColourParams(t *testing.T) {
+	themes := psetter.AllowedVals[string]{
+		"light": "dark text on a light background",
+		"dark":  "light text on a dark background",
+	}
+
+	ps := param.NewSet(quietHelper{})
+	c := AddCLIColourParams(ps, themes, "light")
+
+	var fg color.RGBA //nolint:misspell
+
+	ps.Add("fg", RGB{Value: &fg, Scope: c.Scope}, "the text colour")
+
+	testhelper.DiffString(t, "defaults", "mode", string(c.Mode),
+		string(ColourModeAuto))
+	testhelper.DiffString(t, "defaults", "theme", c.Theme, "light")
+
+	ps.Parse([]string{
+		"-color", "never",
+		"-fg", "navy",
+		"-colour-families", "web",
+		"-list-colours",
+		"-theme", "dark",
+	})
+
+	errs := []error{}
+	for name, paramErrs := range ps.Errors() {
+		for _, err := range paramErrs {
+			errs = append(errs, errors.New(name+": "+err.Error()))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal("unexpected errors: ", err)
+	}
+
+	testhelper.DiffString(t, "parsed", "mode", string(c.Mode),
+		string(ColourModeNever))
+	testhelper.DiffString(t, "parsed", "families", c.Families.String(),
+		colour.Families{colour.WebColours}.String())
+	testhelper.DiffBool(t, "parsed", "list colours", c.ListColours, true)
+	testhelper.DiffString(t, "parsed", "theme", c.Theme, "dark")
+	testhelper.DiffString(t, "parsed", "fg", ChannelOrderRGBA.Hex(fg),
+		"#000080ff")
+	testhelper.DiffBool(t, "parsed", "enabled", c.Enabled(), false)
+
+	ps = param.NewSet(quietHelper{})
+	c = AddCLIColourParams(ps, nil, "")
+
+	if _, err := ps.GetParamByName(CLIThemeParamName); err == nil {
+		t.Error("the theme parameter should only be added if there are themes")
+	}
+
+	ps.Add("fg", RGB{Value: &fg, Scope: c.Scope}, "the text colour")
+	ps.Parse([]string{"-fg", "lavender", "-colour-families", "web"})
+
+	if len(ps.Errors()["fg"]) == 0 {
+		t.Error("the fg colour should be interpreted using the" +
+			" colour-families given after it")
+	}
+
+	panicked, panicVal := testhelper.PanicSafe(func() {
+		AddCLIColourParams(param.NewSet(quietHelper{}), themes, "solarized")
+	})
+	testhelper.CheckExpPanic(t, panicked, panicVal,
+		struct {
+			testhelper.ID
+			testhelper.ExpPanic
+		}{
+			ID: testhelper.MkID("bad default theme"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.AddCLIColourParams:" +
+					` the default theme ("solarized") is not one of the themes`),
+		})
+}
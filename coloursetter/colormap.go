@@ -0,0 +1,441 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// These are the parts of the colormap syntax
+const (
+	colormapBrewerPrefix = "brewer:"
+	colormapReverseSfx   = "_r"
+	colormapTruncOpen    = "["
+	colormapTruncClose   = "]"
+	colormapTruncSep     = ":"
+)
+
+// Gradient is a sequence of colours which can be sampled either
+// continuously or as a list of discrete colours. Only the part of the
+// sequence between Start and End is used; if Start is greater than End the
+// sequence is reversed.
+type Gradient struct {
+	// Name is the name of the gradient as given to the Colormap setter
+	Name string
+	// Stops holds the colours, evenly spaced along the gradient
+	Stops []color.RGBA //nolint:misspell
+	// Discrete, if set, means that the gradient is a set of separate
+	// colour classes with no colours between them
+	Discrete bool
+	// Start and End give the portion of the gradient that is used, they
+	// are in the range 0.0 to 1.0
+	Start, End float64
+}
+
+// MakeGradient returns a Gradient having the colours and using the whole of
+// the range
+func MakeGradient(name string, stops []color.RGBA, discrete bool) Gradient { //nolint:misspell
+	return Gradient{
+		Name:     name,
+		Stops:    stops,
+		Discrete: discrete,
+		Start:    0,
+		End:      1,
+	}
+}
+
+// Reverse returns the Gradient with the order of the colours reversed
+func (g Gradient) Reverse() Gradient {
+	g.Start, g.End = g.End, g.Start
+
+	return g
+}
+
+// Truncate returns the part of the Gradient between lo and hi, which
+// should be in the range 0.0 to 1.0, with lo less than hi. The positions
+// are relative to the current Gradient so truncating a reversed Gradient
+// takes the part measured from its (new) start.
+func (g Gradient) Truncate(lo, hi float64) Gradient {
+	start := g.pos(lo)
+	end := g.pos(hi)
+	g.Start, g.End = start, end
+
+	return g
+}
+
+// pos maps the position on the Gradient to a position on the full range of
+// Stops
+func (g Gradient) pos(t float64) float64 {
+	t = max(0, min(1, t))
+
+	return g.Start + t*(g.End-g.Start)
+}
+
+// lerp interpolates between the two channel values
+func lerp(v1, v2 uint8, f float64) uint8 {
+	return clampUint8(float64(v1) + f*(float64(v2)-float64(v1)))
+}
+
+// At returns the colour at position t (in the range 0.0 to 1.0) along the
+// Gradient. For a continuous Gradient the colour is interpolated between
+// the nearest Stops; for a discrete Gradient it is the colour of the class
+// containing the position.
+func (g Gradient) At(t float64) color.RGBA { //nolint:misspell
+	n := len(g.Stops)
+	if n == 0 {
+		return color.RGBA{} //nolint:misspell
+	}
+
+	p := g.pos(t)
+
+	if g.Discrete {
+		return g.Stops[min(int(p*float64(n)), n-1)]
+	}
+
+	if n == 1 {
+		return g.Stops[0]
+	}
+
+	idx, frac := math.Modf(p * float64(n-1))
+	i := int(idx)
+
+	if i >= n-1 {
+		return g.Stops[n-1]
+	}
+
+	c1, c2 := g.Stops[i], g.Stops[i+1]
+
+	return color.RGBA{ //nolint:misspell
+		R: lerp(c1.R, c2.R, frac),
+		G: lerp(c1.G, c2.G, frac),
+		B: lerp(c1.B, c2.B, frac),
+		A: lerp(c1.A, c2.A, frac),
+	}
+}
+
+// Colours returns n colours from the Gradient. For a continuous Gradient
+// they are evenly spaced from the start to the end; for a discrete Gradient
+// they are taken from the middle of n evenly sized classes. If n is less
+// than or equal to zero the colours of a discrete Gradient are its classes
+// (within the range that is used), a continuous Gradient gives no colours.
+func (g Gradient) Colours(n int) []color.RGBA { //nolint:misspell
+	if n <= 0 {
+		if !g.Discrete {
+			return []color.RGBA{} //nolint:misspell
+		}
+
+		return g.classes()
+	}
+
+	colours := make([]color.RGBA, 0, n) //nolint:misspell
+
+	for i := range n {
+		var t float64
+
+		switch {
+		case g.Discrete:
+			t = (float64(i) + 0.5) / float64(n) //nolint:mnd
+		case n == 1:
+			t = 0.5 //nolint:mnd
+		default:
+			t = float64(i) / float64(n-1)
+		}
+
+		colours = append(colours, g.At(t))
+	}
+
+	return colours
+}
+
+// classes returns those Stops whose class centre lies within the range of
+// the Gradient that is used, in order from the Start to the End
+func (g Gradient) classes() []color.RGBA { //nolint:misspell
+	lo, hi := min(g.Start, g.End), max(g.Start, g.End)
+	n := float64(len(g.Stops))
+
+	colours := []color.RGBA{} //nolint:misspell
+
+	for i, c := range g.Stops {
+		centre := (float64(i) + 0.5) / n //nolint:mnd
+		if centre >= lo && centre <= hi {
+			colours = append(colours, c)
+		}
+	}
+
+	if g.Start > g.End {
+		slices.Reverse(colours)
+	}
+
+	return colours
+}
+
+// mustParseHexList converts the list of hex colours into RGBA values. It
+// is only used with the package's colour tables and panics if any value is
+// invalid.
+func mustParseHexList(hexVals []string) []color.RGBA { //nolint:misspell
+	colours := make([]color.RGBA, 0, len(hexVals)) //nolint:misspell
+
+	for _, h := range hexVals {
+		c, err := ChannelOrderRGB.ParseHex(h)
+		if err != nil {
+			panic(fmt.Sprintf("bad colour in the colormap tables: %q: %s",
+				h, err))
+		}
+
+		colours = append(colours, c)
+	}
+
+	return colours
+}
+
+// findBrewerScheme returns the name and the scheme of the ColorBrewer
+// scheme. The search is case-blind.
+func findBrewerScheme(name string) (string, brewerScheme, bool) {
+	for k, bs := range brewerSchemes {
+		if strings.EqualFold(k, name) {
+			return k, bs, true
+		}
+	}
+
+	return "", brewerScheme{}, false
+}
+
+// maxClasses returns the largest number of classes for the scheme
+func (bs brewerScheme) maxClasses() int {
+	if bs.kind == brewerQualitative {
+		return len(bs.colours)
+	}
+
+	return slices.Max(slices.Collect(maps.Keys(brewerPicks[bs.kind])))
+}
+
+// classColours returns the colours for the given number of classes. The
+// number should have already been checked.
+func (bs brewerScheme) classColours(n int) []string {
+	if bs.kind == brewerQualitative {
+		return bs.colours[:n]
+	}
+
+	hexVals := make([]string, 0, n)
+	for _, i := range brewerPicks[bs.kind][n] {
+		hexVals = append(hexVals, bs.colours[i])
+	}
+
+	return hexVals
+}
+
+// parseBrewer parses the ColorBrewer scheme name and class count
+func parseBrewer(s string) (Gradient, error) {
+	name, countStr, found := strings.Cut(s, ":")
+	if !found {
+		return Gradient{},
+			fmt.Errorf("bad ColorBrewer scheme %q:"+
+				" the scheme name must be followed by :"+
+				" and the number of classes",
+				colormapBrewerPrefix+s)
+	}
+
+	schemeName, bs, ok := findBrewerScheme(strings.TrimSpace(name))
+	if !ok {
+		return Gradient{},
+			fmt.Errorf("unknown ColorBrewer scheme: %q", name)
+	}
+
+	const minClasses = 3
+
+	n, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil || n < minClasses || n > bs.maxClasses() {
+		return Gradient{},
+			fmt.Errorf("bad number of classes for the ColorBrewer scheme %s:"+
+				" %q (it must be from %d to %d)",
+				schemeName, countStr, minClasses, bs.maxClasses())
+	}
+
+	return MakeGradient(
+			fmt.Sprintf("%s%s:%d", colormapBrewerPrefix, schemeName, n),
+			mustParseHexList(bs.classColours(n)),
+			true),
+		nil
+}
+
+// parseTruncation parses the lo:hi truncation range
+func parseTruncation(s string) (float64, float64, error) {
+	loStr, hiStr, found := strings.Cut(s, colormapTruncSep)
+	if !found {
+		return 0, 0,
+			fmt.Errorf("bad colormap range %q: expected lo%shi", s,
+				colormapTruncSep)
+	}
+
+	lo, errLo := strconv.ParseFloat(strings.TrimSpace(loStr), 64)
+	hi, errHi := strconv.ParseFloat(strings.TrimSpace(hiStr), 64)
+
+	if errLo != nil || errHi != nil ||
+		lo < 0 || hi > 1 || lo >= hi {
+		return 0, 0,
+			fmt.Errorf("bad colormap range %q:"+
+				" the values must be from 0.0 to 1.0"+
+				" and the first must be less than the second", s)
+	}
+
+	return lo, hi, nil
+}
+
+// ParseColormap parses the colormap description and returns the
+// corresponding Gradient. See the Colormap setter for the syntax.
+func ParseColormap(s string) (Gradient, error) {
+	val := strings.TrimSpace(s)
+
+	var (
+		truncated bool
+		lo, hi    float64
+	)
+
+	if strings.HasSuffix(val, colormapTruncClose) {
+		base, rng, found := cutLast(
+			strings.TrimSuffix(val, colormapTruncClose), colormapTruncOpen)
+		if !found {
+			return Gradient{},
+				fmt.Errorf("bad colormap %q: missing %q",
+					s, colormapTruncOpen)
+		}
+
+		var err error
+
+		lo, hi, err = parseTruncation(rng)
+		if err != nil {
+			return Gradient{}, err
+		}
+
+		val = strings.TrimSpace(base)
+		truncated = true
+	}
+
+	reversed := false
+	if strings.HasSuffix(strings.ToLower(val), colormapReverseSfx) {
+		val = val[:len(val)-len(colormapReverseSfx)]
+		reversed = true
+	}
+
+	var g Gradient
+
+	if strings.HasPrefix(strings.ToLower(val), colormapBrewerPrefix) {
+		var err error
+
+		g, err = parseBrewer(val[len(colormapBrewerPrefix):])
+		if err != nil {
+			return Gradient{}, err
+		}
+	} else {
+		name := strings.ToLower(val)
+
+		stops, ok := namedColormaps[name]
+		if !ok {
+			return Gradient{}, fmt.Errorf("unknown colormap: %q", val)
+		}
+
+		g = MakeGradient(name, mustParseHexList(stops), false)
+	}
+
+	if reversed {
+		g = g.Reverse()
+		g.Name += colormapReverseSfx
+	}
+
+	if truncated {
+		g = g.Truncate(lo, hi)
+		g.Name += fmt.Sprintf("%s%g%s%g%s",
+			colormapTruncOpen, lo, colormapTruncSep, hi, colormapTruncClose)
+	}
+
+	return g, nil
+}
+
+// Colormap is used to set a Gradient from the name of a perceptually
+// uniform colormap (such as viridis) or a ColorBrewer scheme with a number
+// of classes (such as brewer:RdYlBu:7). The name may be followed by _r to
+// reverse the colours and then by [lo:hi] to use only part of the map.
+type Colormap struct {
+	psetter.ValueReqMandatory
+
+	Value *Gradient
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// colormap and, if it is valid, sets the Value.
+func (s Colormap) SetWithVal(_ string, paramVal string) error {
+	g, err := ParseColormap(paramVal)
+	if err == nil {
+		*s.Value = g
+	}
+
+	return err
+}
+
+// brewerNames returns the names of the ColorBrewer schemes of the given
+// kind, in sorted order
+func brewerNames(kind brewerKind) []string {
+	names := []string{}
+
+	for name, bs := range brewerSchemes {
+		if bs.kind == kind {
+			names = append(names, fmt.Sprintf("%s (%d)", name, bs.maxClasses()))
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Colormap) AllowedValues() string {
+	avals := "the name of a colormap: " +
+		strings.Join(slices.Sorted(maps.Keys(namedColormaps)), ", ") +
+		"\n\n" +
+		"Or " + colormapBrewerPrefix + "Scheme:N" +
+		" for N classes of a ColorBrewer scheme." +
+		" N must be at least 3 and at most" +
+		" the number shown in brackets after the scheme name."
+
+	for _, kind := range []brewerKind{
+		brewerSequential, brewerDiverging, brewerQualitative,
+	} {
+		avals += "\n" + kind.String() + ": " +
+			strings.Join(brewerNames(kind), ", ")
+	}
+
+	return avals +
+		"\n\n" +
+		"The name may be followed by " + colormapReverseSfx +
+		" to reverse the order of the colours" +
+		" and then by " + colormapTruncOpen + "lo" + colormapTruncSep +
+		"hi" + colormapTruncClose + " (values from 0.0 to 1.0)" +
+		" to use only that part of the colormap"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Colormap) ValDescribe() string {
+	return "colormap"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Colormap) CurrentValue() string {
+	return s.Value.Name
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil.
+func (s Colormap) CheckSetter(name string) {
+	if s.Value == nil {
+		panic(name + ": coloursetter.Colormap Check failed:" +
+			" Colormap.Value: is nil")
+	}
+}
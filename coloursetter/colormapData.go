@@ -0,0 +1,266 @@
+package coloursetter
+
+// namedColormaps holds the perceptually uniform colormaps. Each is given as
+// colours sampled at evenly spaced points along the map; intermediate
+// colours are found by interpolation.
+var namedColormaps = map[string][]string{
+	"viridis": {
+		"#440154", "#482475", "#414487", "#355f8d", "#2a788e",
+		"#21918c", "#22a884", "#44bf70", "#7ad151", "#bddf26", "#fde725",
+	},
+	"magma": {
+		"#000004", "#140e36", "#3b0f70", "#641a80", "#8c2981",
+		"#b73779", "#de4968", "#f7705c", "#fe9f6d", "#fecf92", "#fcfdbf",
+	},
+	"inferno": {
+		"#000004", "#160b39", "#420a68", "#6a176e", "#932667",
+		"#bc3754", "#dd513a", "#f37819", "#fca50a", "#f6d746", "#fcffa4",
+	},
+	"plasma": {
+		"#0d0887", "#41049d", "#6a00a8", "#8f0da4", "#b12a90",
+		"#cc4778", "#e16462", "#f2844b", "#fca636", "#fcce25", "#f0f921",
+	},
+	"cividis": {
+		"#00224e", "#083370", "#35456c", "#4f576c", "#666970",
+		"#7d7c78", "#948e77", "#aea371", "#c8b866", "#e5cf52", "#fee838",
+	},
+	"turbo": {
+		"#30123b", "#4145ab", "#4675ed", "#39a2fc", "#1bcfd4",
+		"#24eca6", "#61fc6c", "#a4fc3b", "#d1e834", "#f3c63a",
+		"#fe9b2d", "#f36315", "#d93806", "#b11901", "#7a0402",
+	},
+}
+
+// brewerKind identifies the type of a ColorBrewer scheme
+type brewerKind int
+
+const (
+	brewerSequential brewerKind = iota
+	brewerDiverging
+	brewerQualitative
+)
+
+// String returns the name of the kind of scheme
+func (bk brewerKind) String() string {
+	switch bk {
+	case brewerSequential:
+		return "sequential"
+	case brewerDiverging:
+		return "diverging"
+	}
+
+	return "qualitative"
+}
+
+// brewerScheme holds the colours of a ColorBrewer scheme. For qualitative
+// schemes the colours are those of the largest set of classes and a smaller
+// set of classes is the first colours of the list. For the sequential and
+// diverging schemes the colours are the full list from which the colours
+// for each number of classes are chosen (see brewerPicks).
+type brewerScheme struct {
+	kind    brewerKind
+	colours []string
+}
+
+// brewerPicks gives, for the sequential and diverging schemes, the indexes
+// into the scheme's colours of the colours for each number of
+// classes. These are the same for every scheme of the kind.
+var brewerPicks = map[brewerKind]map[int][]int{
+	brewerSequential: {
+		3: {2, 5, 8},
+		4: {1, 4, 6, 9},
+		5: {1, 4, 6, 8, 10},
+		6: {1, 3, 5, 6, 8, 10},
+		7: {1, 3, 5, 6, 7, 9, 11},
+		8: {0, 2, 3, 5, 6, 7, 9, 11},
+		9: {0, 2, 3, 5, 6, 7, 9, 10, 12},
+	},
+	brewerDiverging: {
+		3:  {4, 7, 10},
+		4:  {2, 5, 9, 13},
+		5:  {2, 5, 7, 9, 13},
+		6:  {1, 4, 6, 8, 10, 12},
+		7:  {1, 4, 6, 7, 8, 10, 12},
+		8:  {1, 3, 5, 6, 8, 9, 11, 12},
+		9:  {1, 3, 5, 6, 7, 8, 9, 11, 12},
+		10: {0, 1, 3, 5, 6, 8, 9, 11, 12, 14},
+		11: {0, 1, 3, 5, 6, 7, 8, 9, 11, 12, 14},
+	},
+}
+
+// brewerSchemes holds the ColorBrewer schemes (colours by Cynthia Brewer,
+// see colorbrewer2.org) keyed by their names
+var brewerSchemes = map[string]brewerScheme{
+	// sequential
+	"Blues": {brewerSequential, []string{
+		"#f7fbff", "#eff3ff", "#deebf7", "#c6dbef", "#bdd7e7",
+		"#9ecae1", "#6baed6", "#4292c6", "#3182bd", "#2171b5",
+		"#08519c", "#084594", "#08306b",
+	}},
+	"BuGn": {brewerSequential, []string{
+		"#f7fcfd", "#edf8fb", "#e5f5f9", "#ccece6", "#b2e2e2",
+		"#99d8c9", "#66c2a4", "#41ae76", "#2ca25f", "#238b45",
+		"#006d2c", "#005824", "#00441b",
+	}},
+	"BuPu": {brewerSequential, []string{
+		"#f7fcfd", "#edf8fb", "#e0ecf4", "#bfd3e6", "#b3cde3",
+		"#9ebcda", "#8c96c6", "#8c6bb1", "#8856a7", "#88419d",
+		"#810f7c", "#6e016b", "#4d004b",
+	}},
+	"GnBu": {brewerSequential, []string{
+		"#f7fcf0", "#f0f9e8", "#e0f3db", "#ccebc5", "#bae4bc",
+		"#a8ddb5", "#7bccc4", "#4eb3d3", "#43a2ca", "#2b8cbe",
+		"#0868ac", "#08589e", "#084081",
+	}},
+	"Greens": {brewerSequential, []string{
+		"#f7fcf5", "#edf8e9", "#e5f5e0", "#c7e9c0", "#bae4b3",
+		"#a1d99b", "#74c476", "#41ab5d", "#31a354", "#238b45",
+		"#006d2c", "#005a32", "#00441b",
+	}},
+	"Greys": {brewerSequential, []string{
+		"#ffffff", "#f7f7f7", "#f0f0f0", "#d9d9d9", "#cccccc",
+		"#bdbdbd", "#969696", "#737373", "#636363", "#525252",
+		"#252525", "#252525", "#000000",
+	}},
+	"Oranges": {brewerSequential, []string{
+		"#fff5eb", "#feedde", "#fee6ce", "#fdd0a2", "#fdbe85",
+		"#fdae6b", "#fd8d3c", "#f16913", "#e6550d", "#d94801",
+		"#a63603", "#8c2d04", "#7f2704",
+	}},
+	"OrRd": {brewerSequential, []string{
+		"#fff7ec", "#fef0d9", "#fee8c8", "#fdd49e", "#fdcc8a",
+		"#fdbb84", "#fc8d59", "#ef6548", "#e34a33", "#d7301f",
+		"#b30000", "#990000", "#7f0000",
+	}},
+	"PuBu": {brewerSequential, []string{
+		"#fff7fb", "#f1eef6", "#ece7f2", "#d0d1e6", "#bdc9e1",
+		"#a6bddb", "#74a9cf", "#3690c0", "#2b8cbe", "#0570b0",
+		"#045a8d", "#034e7b", "#023858",
+	}},
+	"PuBuGn": {brewerSequential, []string{
+		"#fff7fb", "#f6eff7", "#ece2f0", "#d0d1e6", "#bdc9e1",
+		"#a6bddb", "#67a9cf", "#3690c0", "#1c9099", "#02818a",
+		"#016c59", "#016450", "#014636",
+	}},
+	"PuRd": {brewerSequential, []string{
+		"#f7f4f9", "#f1eef6", "#e7e1ef", "#d4b9da", "#d7b5d8",
+		"#c994c7", "#df65b0", "#e7298a", "#dd1c77", "#ce1256",
+		"#980043", "#91003f", "#67001f",
+	}},
+	"Purples": {brewerSequential, []string{
+		"#fcfbfd", "#f2f0f7", "#efedf5", "#dadaeb", "#cbc9e2",
+		"#bcbddc", "#9e9ac8", "#807dba", "#756bb1", "#6a51a3",
+		"#54278f", "#4a1486", "#3f007d",
+	}},
+	"RdPu": {brewerSequential, []string{
+		"#fff7f3", "#feebe2", "#fde0dd", "#fcc5c0", "#fbb4b9",
+		"#fa9fb5", "#f768a1", "#dd3497", "#c51b8a", "#ae017e",
+		"#7a0177", "#7a0177", "#49006a",
+	}},
+	"Reds": {brewerSequential, []string{
+		"#fff5f0", "#fee5d9", "#fee0d2", "#fcbba1", "#fcae91",
+		"#fc9272", "#fb6a4a", "#ef3b2c", "#de2d26", "#cb181d",
+		"#a50f15", "#99000d", "#67000d",
+	}},
+	"YlGn": {brewerSequential, []string{
+		"#ffffe5", "#ffffcc", "#f7fcb9", "#d9f0a3", "#c2e699",
+		"#addd8e", "#78c679", "#41ab5d", "#31a354", "#238443",
+		"#006837", "#005a32", "#004529",
+	}},
+	"YlGnBu": {brewerSequential, []string{
+		"#ffffd9", "#ffffcc", "#edf8b1", "#c7e9b4", "#a1dab4",
+		"#7fcdbb", "#41b6c4", "#1d91c0", "#2c7fb8", "#225ea8",
+		"#253494", "#0c2c84", "#081d58",
+	}},
+	"YlOrBr": {brewerSequential, []string{
+		"#ffffe5", "#ffffd4", "#fff7bc", "#fee391", "#fed98e",
+		"#fec44f", "#fe9929", "#ec7014", "#d95f0e", "#cc4c02",
+		"#993404", "#8c2d04", "#662506",
+	}},
+	"YlOrRd": {brewerSequential, []string{
+		"#ffffcc", "#ffffb2", "#ffeda0", "#fed976", "#fecc5c",
+		"#feb24c", "#fd8d3c", "#fc4e2a", "#f03b20", "#e31a1c",
+		"#bd0026", "#b10026", "#800026",
+	}},
+
+	// diverging
+	"BrBG": {brewerDiverging, []string{
+		"#543005", "#8c510a", "#a6611a", "#bf812d", "#d8b365",
+		"#dfc27d", "#f6e8c3", "#f5f5f5", "#c7eae5", "#80cdc1",
+		"#5ab4ac", "#35978f", "#01665e", "#018571", "#003c30",
+	}},
+	"PiYG": {brewerDiverging, []string{
+		"#8e0152", "#c51b7d", "#d01c8b", "#de77ae", "#e9a3c9",
+		"#f1b6da", "#fde0ef", "#f7f7f7", "#e6f5d0", "#b8e186",
+		"#a1d76a", "#7fbc41", "#4d9221", "#4dac26", "#276419",
+	}},
+	"PRGn": {brewerDiverging, []string{
+		"#40004b", "#762a83", "#7b3294", "#9970ab", "#af8dc3",
+		"#c2a5cf", "#e7d4e8", "#f7f7f7", "#d9f0d3", "#a6dba0",
+		"#7fbf7b", "#5aae61", "#1b7837", "#008837", "#00441b",
+	}},
+	"PuOr": {brewerDiverging, []string{
+		"#7f3b08", "#b35806", "#e66101", "#e08214", "#f1a340",
+		"#fdb863", "#fee0b6", "#f7f7f7", "#d8daeb", "#b2abd2",
+		"#998ec3", "#8073ac", "#542788", "#5e3c99", "#2d004b",
+	}},
+	"RdBu": {brewerDiverging, []string{
+		"#67001f", "#b2182b", "#ca0020", "#d6604d", "#ef8a62",
+		"#f4a582", "#fddbc7", "#f7f7f7", "#d1e5f0", "#92c5de",
+		"#67a9cf", "#4393c3", "#2166ac", "#0571b0", "#053061",
+	}},
+	"RdGy": {brewerDiverging, []string{
+		"#67001f", "#b2182b", "#ca0020", "#d6604d", "#ef8a62",
+		"#f4a582", "#fddbc7", "#ffffff", "#e0e0e0", "#bababa",
+		"#999999", "#878787", "#4d4d4d", "#404040", "#1a1a1a",
+	}},
+	"RdYlBu": {brewerDiverging, []string{
+		"#a50026", "#d73027", "#d7191c", "#f46d43", "#fc8d59",
+		"#fdae61", "#fee090", "#ffffbf", "#e0f3f8", "#abd9e9",
+		"#91bfdb", "#74add1", "#4575b4", "#2c7bb6", "#313695",
+	}},
+	"RdYlGn": {brewerDiverging, []string{
+		"#a50026", "#d73027", "#d7191c", "#f46d43", "#fc8d59",
+		"#fdae61", "#fee08b", "#ffffbf", "#d9ef8b", "#a6d96a",
+		"#91cf60", "#66bd63", "#1a9850", "#1a9641", "#006837",
+	}},
+	"Spectral": {brewerDiverging, []string{
+		"#9e0142", "#d53e4f", "#d7191c", "#f46d43", "#fc8d59",
+		"#fdae61", "#fee08b", "#ffffbf", "#e6f598", "#abdda4",
+		"#99d594", "#66c2a5", "#3288bd", "#2b83ba", "#5e4fa2",
+	}},
+
+	// qualitative
+	"Accent": {brewerQualitative, []string{
+		"#7fc97f", "#beaed4", "#fdc086", "#ffff99",
+		"#386cb0", "#f0027f", "#bf5b17", "#666666",
+	}},
+	"Dark2": {brewerQualitative, []string{
+		"#1b9e77", "#d95f02", "#7570b3", "#e7298a",
+		"#66a61e", "#e6ab02", "#a6761d", "#666666",
+	}},
+	"Paired": {brewerQualitative, []string{
+		"#a6cee3", "#1f78b4", "#b2df8a", "#33a02c", "#fb9a99", "#e31a1c",
+		"#fdbf6f", "#ff7f00", "#cab2d6", "#6a3d9a", "#ffff99", "#b15928",
+	}},
+	"Pastel1": {brewerQualitative, []string{
+		"#fbb4ae", "#b3cde3", "#ccebc5", "#decbe4", "#fed9a6",
+		"#ffffcc", "#e5d8bd", "#fddaec", "#f2f2f2",
+	}},
+	"Pastel2": {brewerQualitative, []string{
+		"#b3e2cd", "#fdcdac", "#cbd5e8", "#f4cae4",
+		"#e6f5c9", "#fff2ae", "#f1e2cc", "#cccccc",
+	}},
+	"Set1": {brewerQualitative, []string{
+		"#e41a1c", "#377eb8", "#4daf4a", "#984ea3", "#ff7f00",
+		"#ffff33", "#a65628", "#f781bf", "#999999",
+	}},
+	"Set2": {brewerQualitative, []string{
+		"#66c2a5", "#fc8d62", "#8da0cb", "#e78ac3",
+		"#a6d854", "#ffd92f", "#e5c494", "#b3b3b3",
+	}},
+	"Set3": {brewerQualitative, []string{
+		"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3", "#fdb462",
+		"#b3de69", "#fccde5", "#d9d9d9", "#bc80bd", "#ccebc5", "#ffed6f",
+	}},
+}
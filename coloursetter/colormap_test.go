@@ -0,0 +1,202 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"strings"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// hexList returns the colours as a comma-separated list of RGB hex values
+func hexList(colours []color.RGBA) string { //nolint:misspell
+	hexVals := []string{}
+	for _, c := range colours {
+		hexVals = append(hexVals, ChannelOrderRGB.Hex(c))
+	}
+
+	return strings.Join(hexVals, ",")
+}
+
+func TestColormapData(t *testing.T) {
+	for name, stops := range namedColormaps {
+		if len(mustParseHexList(stops)) < 2 {
+			t.Errorf("colormap %s: too few colours", name)
+		}
+	}
+
+	expLen := map[brewerKind]int{brewerSequential: 13, brewerDiverging: 15}
+
+	for name, bs := range brewerSchemes {
+		mustParseHexList(bs.colours)
+
+		if bs.kind == brewerQualitative {
+			continue
+		}
+
+		if len(bs.colours) != expLen[bs.kind] {
+			t.Errorf("ColorBrewer scheme %s: has %d colours, expected %d",
+				name, len(bs.colours), expLen[bs.kind])
+		}
+	}
+
+	for kind, picks := range brewerPicks {
+		for n, idxs := range picks {
+			if len(idxs) != n {
+				t.Errorf("%s picks for %d classes: %d indexes given",
+					kind, n, len(idxs))
+			}
+		}
+	}
+}
+
+func TestParseColormap(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v       string
+		n       int
+		expName string
+		expVal  string
+	}{
+		{
+			ID:      testhelper.MkID("viridis - ends and middle"),
+			v:       "Viridis",
+			n:       3,
+			expName: "viridis",
+			expVal:  "#440154,#21918c,#fde725",
+		},
+		{
+			ID:      testhelper.MkID("viridis - reversed"),
+			v:       "viridis_r",
+			n:       2,
+			expName: "viridis_r",
+			expVal:  "#fde725,#440154",
+		},
+		{
+			ID:      testhelper.MkID("viridis - truncated"),
+			v:       "viridis[0:0.5]",
+			n:       2,
+			expName: "viridis[0:0.5]",
+			expVal:  "#440154,#21918c",
+		},
+		{
+			ID:      testhelper.MkID("viridis - reversed and truncated"),
+			v:       "viridis_r[0.5:1]",
+			n:       2,
+			expName: "viridis_r[0.5:1]",
+			expVal:  "#21918c,#440154",
+		},
+		{
+			ID:      testhelper.MkID("brewer - sequential"),
+			v:       "brewer:blues:5",
+			expName: "brewer:Blues:5",
+			expVal:  "#eff3ff,#bdd7e7,#6baed6,#3182bd,#08519c",
+		},
+		{
+			ID:      testhelper.MkID("brewer - diverging"),
+			v:       "brewer:RdYlBu:7",
+			expName: "brewer:RdYlBu:7",
+			expVal: "#d73027,#fc8d59,#fee090,#ffffbf," +
+				"#e0f3f8,#91bfdb,#4575b4",
+		},
+		{
+			ID:      testhelper.MkID("brewer - diverging, reversed"),
+			v:       "brewer:RdBu:4_r",
+			expName: "brewer:RdBu:4_r",
+			expVal:  "#0571b0,#92c5de,#f4a582,#ca0020",
+		},
+		{
+			ID:      testhelper.MkID("brewer - diverging, truncated"),
+			v:       "brewer:RdBu:4[0.5:1]",
+			expName: "brewer:RdBu:4[0.5:1]",
+			expVal:  "#92c5de,#0571b0",
+		},
+		{
+			ID:      testhelper.MkID("brewer - qualitative"),
+			v:       "brewer:Set1:3",
+			expName: "brewer:Set1:3",
+			expVal:  "#e41a1c,#377eb8,#4daf4a",
+		},
+		{
+			ID:      testhelper.MkID("brewer - sampled"),
+			v:       "brewer:Set1:3",
+			n:       6,
+			expName: "brewer:Set1:3",
+			expVal: "#e41a1c,#e41a1c,#377eb8," +
+				"#377eb8,#4daf4a,#4daf4a",
+		},
+		{
+			ID:     testhelper.MkID("unknown colormap"),
+			ExpErr: testhelper.MkExpErr(`unknown colormap: "jet"`),
+			v:      "jet",
+		},
+		{
+			ID:     testhelper.MkID("unknown brewer scheme"),
+			ExpErr: testhelper.MkExpErr(`unknown ColorBrewer scheme: "Jet"`),
+			v:      "brewer:Jet:3",
+		},
+		{
+			ID: testhelper.MkID("brewer - no count"),
+			ExpErr: testhelper.MkExpErr(`bad ColorBrewer scheme "brewer:Blues":` +
+				" the scheme name must be followed by : and the number of classes"),
+			v: "brewer:Blues",
+		},
+		{
+			ID: testhelper.MkID("brewer - too many classes"),
+			ExpErr: testhelper.MkExpErr(
+				"bad number of classes for the ColorBrewer scheme Set2:" +
+					` "9" (it must be from 3 to 8)`),
+			v: "brewer:Set2:9",
+		},
+		{
+			ID: testhelper.MkID("bad range"),
+			ExpErr: testhelper.MkExpErr(`bad colormap range "0.6:0.5":` +
+				" the values must be from 0.0 to 1.0" +
+				" and the first must be less than the second"),
+			v: "magma[0.6:0.5]",
+		},
+		{
+			ID:     testhelper.MkID("bad range - missing open bracket"),
+			ExpErr: testhelper.MkExpErr(`bad colormap "magma0:1]": missing "["`),
+			v:      "magma0:1]",
+		},
+	}
+
+	for _, tc := range testCases {
+		g, err := ParseColormap(tc.v)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "name", g.Name, tc.expName)
+			testhelper.DiffString(t, tc.IDStr(), "colours",
+				hexList(g.Colours(tc.n)), tc.expVal)
+		}
+	}
+}
+
+func TestGradientAt(t *testing.T) {
+	g := MakeGradient("test",
+		[]color.RGBA{{A: 0xff}, {R: 0xff, G: 0x80, A: 0xff}}, //nolint:misspell
+		false)
+
+	testhelper.DiffString(t, "At", "0.5",
+		ChannelOrderRGB.Hex(g.At(0.5)), "#804000")
+	testhelper.DiffString(t, "At", "below range",
+		ChannelOrderRGB.Hex(g.At(-1)), "#000000")
+	testhelper.DiffString(t, "At", "above range",
+		ChannelOrderRGB.Hex(g.At(2)), "#ff8000")
+	testhelper.DiffString(t, "At", "empty",
+		ChannelOrderRGBA.Hex(Gradient{}.At(0.5)), "#00000000")
+}
+
+func TestColormapAllowedValues(t *testing.T) {
+	av := Colormap{}.AllowedValues()
+
+	for _, name := range []string{
+		"viridis", "magma", "inferno", "plasma", "cividis", "turbo",
+		"RdYlBu (11)", "Blues (9)", "Paired (12)",
+	} {
+		if !strings.Contains(av, name) {
+			t.Errorf("AllowedValues does not mention %q", name)
+		}
+	}
+}
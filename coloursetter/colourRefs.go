@@ -0,0 +1,415 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+)
+
+// These introduce a reference to another colour parameter
+const (
+	refSameAsPrefix = "same-as:"
+	refIntro        = "@"
+
+	refLighten = "lighten"
+	refDarken  = "darken"
+	refAdjust  = "adjust"
+)
+
+// colourRef records a reference to another colour parameter together with
+// any adjustments to be made to its colour
+type colourRef struct {
+	text   string
+	target string
+	tweaks []ColourTweak
+}
+
+// refParam is a colour parameter registered with a ColourRefs
+type refParam struct {
+	name string
+	get  func() color.RGBA               //nolint:misspell
+	set  func(c color.RGBA, name string) //nolint:misspell
+	ref  *colourRef
+}
+
+// refCycleError reports a circular chain of references
+type refCycleError struct {
+	path []string
+}
+
+// Error returns the error message
+func (e refCycleError) Error() string {
+	return "circular reference: " + strings.Join(e.path, " -> ")
+}
+
+// ColourRefs allows the value of a colour parameter to be given by
+// reference to another colour parameter, either as a copy of its colour
+// (same-as:name or @name) or adjusted (lighten(@name, 10%),
+// darken(@name, 10%) or adjust(@name, adjustment, ...) where each
+// adjustment is as for the Tweak setter). Only the RGB and NamedColour
+// setters support references; those having this ColourRefs as their Refs
+// register themselves under their parameter names when they are added to
+// the param set. The references are resolved once all the parameters have
+// been parsed so the order in which the parameters are given does not
+// matter.
+//
+// A typical use would be:
+//
+//	refs := coloursetter.NewColourRefs()
+//
+//	ps.Add("fg", coloursetter.RGB{Value: &fg, Refs: refs}, ...)
+//	ps.Add("hover", coloursetter.RGB{Value: &hover, Refs: refs}, ...)
+//	refs.AddFinalCheck(ps)
+//
+// after which "-hover lighten(@fg, 10%)" will set the hover colour from
+// the fg colour. The final check must be added after that of any
+// FamilyScope and before any other final checks which use the colours.
+type ColourRefs struct {
+	params  map[string]*refParam
+	byValue map[any]*refParam
+	order   []string
+}
+
+// NewColourRefs returns a new, empty, ColourRefs
+func NewColourRefs() *ColourRefs {
+	return &ColourRefs{
+		params:  map[string]*refParam{},
+		byValue: map[any]*refParam{},
+	}
+}
+
+// register records the colour parameter. The value is the pointer to the
+// parameter's Value. It panics if a different value has already been
+// registered under the name.
+func (cr *ColourRefs) register(name string, value any,
+	get func() color.RGBA, //nolint:misspell
+	set func(c color.RGBA, name string), //nolint:misspell
+) {
+	if cr == nil {
+		return
+	}
+
+	if p, ok := cr.params[name]; ok {
+		if cr.byValue[value] == p {
+			return
+		}
+
+		panic(fmt.Sprintf(
+			"coloursetter.ColourRefs: the colour parameter %q"+
+				" is already registered", name))
+	}
+
+	p := &refParam{name: name, get: get, set: set}
+	cr.params[name] = p
+	cr.byValue[value] = p
+	cr.order = append(cr.order, name)
+}
+
+// parseColourRef parses the value as a reference to another colour
+// parameter. It returns false if the value is not a reference.
+func parseColourRef(val string) (*colourRef, bool, error) {
+	trimmed := strings.TrimSpace(val)
+	lc := strings.ToLower(trimmed)
+
+	switch {
+	case strings.HasPrefix(lc, refSameAsPrefix):
+		return makeColourRef(val, trimmed[len(refSameAsPrefix):], nil)
+	case strings.HasPrefix(lc, refIntro):
+		return makeColourRef(val, trimmed[len(refIntro):], nil)
+	}
+
+	funcName, argStr, found := strings.Cut(trimmed, "(")
+	if !found {
+		return nil, false, nil
+	}
+
+	funcName = strings.ToLower(strings.TrimSpace(funcName))
+	if funcName != refLighten && funcName != refDarken && funcName != refAdjust {
+		return nil, false, nil
+	}
+
+	argStr, found = strings.CutSuffix(argStr, ")")
+	if !found {
+		return nil, true,
+			fmt.Errorf("bad colour reference %q: no closing bracket", val)
+	}
+
+	args := strings.Split(argStr, ",")
+
+	target, found := strings.CutPrefix(strings.TrimSpace(args[0]), refIntro)
+	if !found {
+		return nil, true,
+			fmt.Errorf("bad colour reference %q:"+
+				" the first argument must be %sname", val, refIntro)
+	}
+
+	tweakStrs := args[1:]
+
+	switch funcName {
+	case refLighten, refDarken:
+		if len(tweakStrs) != 1 {
+			return nil, true,
+				fmt.Errorf("bad colour reference %q:"+
+					" %s takes 2 arguments, %d given",
+					val, funcName, len(args))
+		}
+
+		op := string(TweakAdd)
+		if funcName == refDarken {
+			op = string(TweakSub)
+		}
+
+		tweakStrs = []string{"lightness" + op + strings.TrimSpace(tweakStrs[0])}
+	case refAdjust:
+		if len(tweakStrs) == 0 {
+			return nil, true,
+				fmt.Errorf("bad colour reference %q:"+
+					" %s needs at least one adjustment", val, funcName)
+		}
+	}
+
+	tweaks := make([]ColourTweak, 0, len(tweakStrs))
+
+	for _, ts := range tweakStrs {
+		t, err := ParseColourTweak(ts)
+		if err != nil {
+			return nil, true, fmt.Errorf("bad colour reference %q: %w", val, err)
+		}
+
+		tweaks = append(tweaks, t)
+	}
+
+	return makeColourRef(val, target, tweaks)
+}
+
+// makeColourRef returns the colourRef for the target. It returns an error
+// if the target name is empty.
+func makeColourRef(val, target string, tweaks []ColourTweak) (
+	*colourRef, bool, error,
+) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, true,
+			fmt.Errorf("bad colour reference %q:"+
+				" the parameter name is missing", val)
+	}
+
+	return &colourRef{
+		text:   strings.TrimSpace(val),
+		target: target,
+		tweaks: tweaks,
+	}, true, nil
+}
+
+// setRef records the value as a reference if it is one. It returns true if
+// the value is a reference (or a badly formed one) together with any error
+// found. If the value is not a reference any earlier reference for the
+// parameter is forgotten.
+func (cr *ColourRefs) setRef(paramName, val string) (bool, error) {
+	if cr == nil {
+		return false, nil
+	}
+
+	ref, isRef, err := parseColourRef(val)
+	if err != nil {
+		return true, err
+	}
+
+	p, ok := cr.params[paramName]
+	if !ok {
+		if isRef {
+			return true, fmt.Errorf("the colour parameter %q"+
+				" cannot take a reference as it is not registered",
+				paramName)
+		}
+
+		return false, nil
+	}
+
+	p.ref = ref
+
+	return isRef, nil
+}
+
+// refText returns the reference used to set the value, if any
+func (cr *ColourRefs) refText(value any) (string, bool) {
+	if cr == nil {
+		return "", false
+	}
+
+	p, ok := cr.byValue[value]
+	if !ok || p.ref == nil {
+		return "", false
+	}
+
+	return p.ref.text, true
+}
+
+// resolve sets the value of each parameter given as a reference. It calls
+// the report function for each reference that cannot be resolved.
+func (cr *ColourRefs) resolve(report func(paramName string, err error)) {
+	const (
+		unvisited = iota
+		inProgress
+		done
+		failed
+	)
+
+	state := map[string]int{}
+	errs := map[string]error{}
+
+	var visit func(name string, path []string) error
+
+	visit = func(name string, path []string) error {
+		p := cr.params[name]
+		path = append(path, name)
+
+		switch state[name] {
+		case inProgress:
+			return refCycleError{path: path}
+		case done:
+			return nil
+		case failed:
+			return errs[name]
+		}
+
+		if p.ref == nil {
+			state[name] = done
+			return nil
+		}
+
+		state[name] = inProgress
+
+		err := cr.resolveRef(p, func(target string) error {
+			return visit(target, path)
+		})
+		if err != nil {
+			state[name] = failed
+			errs[name] = err
+
+			return err
+		}
+
+		state[name] = done
+
+		return nil
+	}
+
+	for _, name := range cr.order {
+		if cr.params[name].ref == nil {
+			continue
+		}
+
+		if err := visit(name, nil); err != nil {
+			report(name, err)
+		}
+	}
+}
+
+// resolveRef sets the parameter's value from the referenced parameter,
+// first calling visit to resolve the referenced parameter
+func (cr *ColourRefs) resolveRef(p *refParam, visit func(string) error) error {
+	target, ok := cr.params[p.ref.target]
+	if !ok {
+		return fmt.Errorf("bad colour reference %q:"+
+			" there is no colour parameter called %q",
+			p.ref.text, p.ref.target)
+	}
+
+	if err := visit(target.name); err != nil {
+		var cycle refCycleError
+		if errors.As(err, &cycle) {
+			return err
+		}
+
+		return fmt.Errorf("bad colour reference %q:"+
+			" the colour parameter %q cannot be resolved",
+			p.ref.text, target.name)
+	}
+
+	c := target.get()
+	for _, t := range p.ref.tweaks {
+		c = t.Apply(c)
+	}
+
+	p.set(c, p.ref.text)
+
+	return nil
+}
+
+// Resolve sets the values of the parameters given as references. It
+// returns an error reporting every reference that cannot be resolved, each
+// attributed to its parameter, or nil if there are none. It need not be
+// called if AddFinalCheck has been used.
+func (cr *ColourRefs) Resolve() error {
+	errs := []error{}
+
+	cr.resolve(func(paramName string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %w", paramName, err))
+	})
+
+	return errors.Join(errs...)
+}
+
+// AddFinalCheck adds a final check to the param set which resolves the
+// references once all the parameters have been parsed. Any errors are
+// reported against the parameter that was given the reference.
+func (cr *ColourRefs) AddFinalCheck(ps *param.PSet) {
+	ps.AddFinalCheck(func() error {
+		cr.resolve(func(paramName string, err error) {
+			ps.AddErr(paramName, err)
+		})
+
+		return nil
+	})
+}
+
+// registerRGB registers the value of an RGB parameter under the parameter
+// name so that it can be given as, or referred to by, a reference. It
+// panics if another value has already been registered under the name.
+func (cr *ColourRefs) registerRGB(name string, v *color.RGBA) { //nolint:misspell
+	cr.register(name, v,
+		func() color.RGBA { return *v },         //nolint:misspell
+		func(c color.RGBA, _ string) { *v = c }, //nolint:misspell
+	)
+}
+
+// registerNamedColour registers the value of a NamedColour parameter under
+// the parameter name so that it can be given as, or referred to by, a
+// reference. It panics if another value has already been registered under
+// the name.
+func (cr *ColourRefs) registerNamedColour(name string, v *colour.NamedColour) {
+	cr.register(name, v,
+		func() color.RGBA { return v.Colour() }, //nolint:misspell
+		func(c color.RGBA, n string) { //nolint:misspell
+			*v = colour.MakeNamedColour(n, c)
+		},
+	)
+}
+
+// currentValue returns the current value prefixed by the reference, if
+// any, used to set it
+func (cr *ColourRefs) currentValue(value any, cv string) string {
+	if ref, ok := cr.refText(value); ok {
+		return ref + " = " + cv
+	}
+
+	return cv
+}
+
+// refAllowedValues describes the colour reference notation
+func refAllowedValues() string {
+	return "\n\n" +
+		"Or a reference to another colour parameter:" +
+		" " + refSameAsPrefix + "name (or " + refIntro + "name)" +
+		" for the same colour, " + refLighten + "(" + refIntro + "name, N%)" +
+		" or " + refDarken + "(" + refIntro + "name, N%)" +
+		" for the colour with its lightness raised or lowered by N," +
+		" or " + refAdjust + "(" + refIntro + "name, adjustment, ...)" +
+		" for the colour with the adjustments applied" +
+		" (see the Tweak setter, for instance, hue+180 or alpha=50%)"
+}
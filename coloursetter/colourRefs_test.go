@@ -0,0 +1,201 @@
+package coloursetter
+
+import (
+	"errors"
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestColourRefs(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		args      []string
+		expFG     string
+		expBorder string
+		expHover  string
+		expName   string
+	}{
+		{
+			ID:        testhelper.MkID("no references"),
+			args:      []string{"-fg", "navy", "-border", "red"},
+			expFG:     "#000080ff",
+			expBorder: "#ff0000ff",
+			expHover:  "#000000ff",
+			expName:   "black #000000ff",
+		},
+		{
+			ID: testhelper.MkID("references given before the colour"),
+			args: []string{
+				"-border", "same-as:fg", "-hover", "lighten(@fg, 10%)",
+				"-name", "@border",
+				"-fg", "navy",
+			},
+			expFG:     "#000080ff",
+			expBorder: "same-as:fg = #000080ff",
+			expHover:  "lighten(@fg, 10%) = #0000b3ff",
+			expName:   "@border #000080ff",
+		},
+		{
+			ID: testhelper.MkID("darken and adjust"),
+			args: []string{
+				"-fg", "#336699",
+				"-border", "darken(@fg, 10%)",
+				"-hover", "adjust(@border, hue+180, alpha=50%)",
+			},
+			expFG:     "#336699ff",
+			expBorder: "darken(@fg, 10%) = #264d73ff",
+			expHover: "adjust(@border, hue+180, alpha=50%)" +
+				" = #734c2680",
+			expName: "black #000000ff",
+		},
+		{
+			ID: testhelper.MkID("reference replaced by a colour"),
+			args: []string{
+				"-fg", "navy", "-border", "@fg", "-border", "red",
+			},
+			expFG:     "#000080ff",
+			expBorder: "#ff0000ff",
+			expHover:  "#000000ff",
+			expName:   "black #000000ff",
+		},
+		{
+			ID: testhelper.MkID("dangling reference"),
+			ExpErr: testhelper.MkExpErr(
+				`border: bad colour reference "@bg":`+
+					` there is no colour parameter called "bg"`,
+				`hover: bad colour reference "same-as:border":`+
+					` the colour parameter "border" cannot be resolved`),
+			args: []string{
+				"-fg", "navy", "-border", "@bg", "-hover", "same-as:border",
+			},
+			expFG:     "#000080ff",
+			expBorder: "@bg = #000000ff",
+			expHover:  "same-as:border = #000000ff",
+			expName:   "black #000000ff",
+		},
+		{
+			ID: testhelper.MkID("circular reference"),
+			ExpErr: testhelper.MkExpErr(
+				"fg: circular reference: fg -> border -> hover -> fg",
+				"border: circular reference: fg -> border -> hover -> fg",
+				"hover: circular reference: fg -> border -> hover -> fg"),
+			args: []string{
+				"-fg", "@border", "-border", "@hover", "-hover", "@fg",
+			},
+			expFG:     "@border = #000000ff",
+			expBorder: "@hover = #000000ff",
+			expHover:  "@fg = #000000ff",
+			expName:   "black #000000ff",
+		},
+		{
+			ID: testhelper.MkID("bad references"),
+			ExpErr: testhelper.MkExpErr(
+				`border: bad colour reference "lighten(fg, 10%)":`+
+					` the first argument must be @name`,
+				`hover: bad colour reference "darken(@fg)":`+
+					` darken takes 2 arguments, 1 given`,
+				`name: bad colour reference "same-as:"`+
+					`: the parameter name is missing`),
+			args: []string{
+				"-fg", "navy",
+				"-border", "lighten(fg, 10%)",
+				"-hover", "darken(@fg)",
+				"-name", "same-as:",
+			},
+			expFG:     "#000080ff",
+			expBorder: "#000000ff",
+			expHover:  "#000000ff",
+			expName:   "black #000000ff",
+		},
+	}
+
+	for _, tc := range testCases {
+		var (
+			fg     = color.RGBA{A: 0xff}                                  //nolint:misspell
+			border = color.RGBA{A: 0xff}                                  //nolint:misspell
+			hover  = color.RGBA{A: 0xff}                                  //nolint:misspell
+			nc     = colour.MakeNamedColour("black", color.RGBA{A: 0xff}) //nolint:misspell
+			ps     = param.NewSet(quietHelper{})
+			refs   = NewColourRefs()
+		)
+
+		fgSetter := RGB{Value: &fg, Refs: refs, ShowHex: true}
+		borderSetter := RGB{Value: &border, Refs: refs, ShowHex: true}
+		hoverSetter := RGB{Value: &hover, Refs: refs, ShowHex: true}
+		nameSetter := NamedColour{Value: &nc, Refs: refs, ShowHex: true}
+
+		ps.Add("fg", fgSetter, "the text colour")
+		ps.Add("border", borderSetter, "the border colour")
+		ps.Add("hover", hoverSetter, "the hover colour")
+		ps.Add("name", nameSetter, "a named colour")
+		refs.AddFinalCheck(ps)
+
+		ps.Parse(tc.args)
+
+		errs := []error{}
+		for _, name := range []string{"fg", "border", "hover", "name"} {
+			for _, err := range ps.Errors()[name] {
+				errs = append(errs, errors.New(name+": "+err.Error()))
+			}
+		}
+
+		testhelper.CheckExpErr(t, errors.Join(errs...), tc)
+		testhelper.DiffString(t, tc.IDStr(), "fg",
+			fgSetter.CurrentValue(), tc.expFG)
+		testhelper.DiffString(t, tc.IDStr(), "border",
+			borderSetter.CurrentValue(), tc.expBorder)
+		testhelper.DiffString(t, tc.IDStr(), "hover",
+			hoverSetter.CurrentValue(), tc.expHover)
+		testhelper.DiffString(t, tc.IDStr(), "name",
+			nc.Name()+" "+ChannelOrderRGBA.Hex(nc.Colour()), tc.expName)
+	}
+}
+
+func TestColourRefsResolve(t *testing.T) {
+	refs := NewColourRefs()
+
+	var fg, bg color.RGBA //nolint:misspell
+
+	fgSetter := RGB{Value: &fg, Refs: refs}
+	bgSetter := RGB{Value: &bg, Refs: refs}
+
+	fgSetter.CheckSetter("fg")
+	bgSetter.CheckSetter("bg")
+
+	if err := bgSetter.SetWithVal("bg", "same-as:text"); err != nil {
+		t.Fatal("the reference should be resolved later, got: ", err)
+	}
+
+	tc := struct {
+		testhelper.ID
+		testhelper.ExpErr
+	}{
+		ID: testhelper.MkID("dangling reference"),
+		ExpErr: testhelper.MkExpErr(
+			`bg: bad colour reference "same-as:text":`,
+			`there is no colour parameter called "text"`),
+	}
+
+	testhelper.CheckExpErr(t, refs.Resolve(), tc)
+
+	panicked, panicVal := testhelper.PanicSafe(func() {
+		var other color.RGBA //nolint:misspell
+
+		RGB{Value: &other, Refs: refs}.CheckSetter("fg")
+	})
+	testhelper.CheckExpPanic(t, panicked, panicVal,
+		struct {
+			testhelper.ID
+			testhelper.ExpPanic
+		}{
+			ID: testhelper.MkID("duplicate registration"),
+			ExpPanic: testhelper.MkExpPanic(
+				`coloursetter.ColourRefs: the colour parameter "fg"` +
+					" is already registered"),
+		})
+}
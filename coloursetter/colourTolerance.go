@@ -0,0 +1,287 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// These are the separators between the colour and the tolerance and the
+// prefixes of the tolerance types
+const (
+	toleranceSep      = "±"
+	toleranceSepASCII = "+/-"
+
+	tolDeltaEPrefix  = "de:"
+	tolChannelPrefix = "ch:"
+)
+
+// ColourMatch holds a target colour and a tolerance within which other
+// colours are taken as matching the target. The tolerance is either a
+// maximum perceptual difference (Delta E) or a maximum difference for each
+// channel.
+type ColourMatch struct {
+	Target color.RGBA //nolint:misspell
+
+	// ByChannel, if set, means that the ChannelTol values are used
+	// rather than the MaxDeltaE
+	ByChannel bool
+
+	// MaxDeltaE is the largest difference, as measured by the Formula,
+	// between the Target and a matching colour
+	MaxDeltaE float64
+	// Formula gives the formula used to measure the difference
+	Formula DeltaEFormula
+
+	// ChannelTol gives the largest difference in each of the red, green,
+	// blue and alpha channels between the Target and a matching colour
+	ChannelTol [4]uint8
+	// CheckAlpha, if set, means that the alpha channel is compared. If it
+	// is not set only the red, green and blue channels are compared.
+	CheckAlpha bool
+}
+
+// String returns the ColourMatch in the form accepted by the
+// ColourTolerance setter
+func (cm ColourMatch) String() string {
+	target := ChannelOrderRGBA.Hex(cm.Target)
+
+	if !cm.ByChannel {
+		return fmt.Sprintf("%s%s%s%g (%s)",
+			target, toleranceSep, tolDeltaEPrefix, cm.MaxDeltaE, cm.Formula)
+	}
+
+	n := 3 // the red, green and blue channels
+	if cm.CheckAlpha {
+		n++
+	}
+
+	tols := make([]string, 0, n)
+	for _, t := range cm.ChannelTol[:n] {
+		tols = append(tols, strconv.Itoa(int(t)))
+	}
+
+	return target + toleranceSep + tolChannelPrefix + strings.Join(tols, ",")
+}
+
+// withinTol returns true if the two channel values differ by no more than
+// the tolerance
+func withinTol(v1, v2, tol uint8) bool {
+	d := int(v1) - int(v2)
+	if d < 0 {
+		d = -d
+	}
+
+	return d <= int(tol)
+}
+
+// Matches returns true if the colour is within the tolerance of the Target
+// colour. Translucent colours are compared using their non-alpha-premultiplied
+// channel values.
+func (cm ColourMatch) Matches(c color.Color) bool { //nolint:misspell
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)          //nolint:misspell,forcetypeassert
+	sample := color.RGBA{R: nc.R, G: nc.G, B: nc.B, A: nc.A} //nolint:misspell
+
+	if !cm.ByChannel {
+		return cm.Formula.DeltaE(cm.Target, sample) <= cm.MaxDeltaE
+	}
+
+	if cm.CheckAlpha && !withinTol(cm.Target.A, sample.A, cm.ChannelTol[3]) {
+		return false
+	}
+
+	return withinTol(cm.Target.R, sample.R, cm.ChannelTol[0]) &&
+		withinTol(cm.Target.G, sample.G, cm.ChannelTol[1]) &&
+		withinTol(cm.Target.B, sample.B, cm.ChannelTol[2])
+}
+
+// parseDeltaETol parses the Delta E tolerance value
+func parseDeltaETol(s string) (float64, error) {
+	tol, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad Delta E tolerance %q: not a number", s)
+	}
+
+	if tol < 0 || math.IsInf(tol, 0) || math.IsNaN(tol) {
+		return 0,
+			fmt.Errorf("bad Delta E tolerance %q:"+
+				" it must be a finite value, greater than or equal to zero", s)
+	}
+
+	return tol, nil
+}
+
+// parseChannelTol parses the per-channel tolerance values. Either one value
+// (applying to the red, green and blue channels), three values (for the red,
+// green and blue channels) or four values (also giving the alpha channel
+// tolerance) may be given.
+func parseChannelTol(s string) ([4]uint8, bool, error) {
+	var tols [4]uint8
+
+	parts := strings.Split(s, ",")
+	if len(parts) != 1 && len(parts) != 3 && len(parts) != 4 {
+		return tols, false,
+			fmt.Errorf("bad channel tolerance %q:"+
+				" 1, 3 or 4 values expected, %d found", s, len(parts))
+	}
+
+	for i, p := range parts {
+		v, err := strconv.ParseUint(strings.TrimSpace(p), 0, 8)
+		if err != nil {
+			return tols, false,
+				fmt.Errorf("bad channel tolerance %q:"+
+					" part %d (%q) must be a number from 0 to 255",
+					s, i+1, p)
+		}
+
+		tols[i] = uint8(v)
+	}
+
+	if len(parts) == 1 {
+		tols[1], tols[2] = tols[0], tols[0]
+	}
+
+	return tols, len(parts) == 4, nil //nolint:mnd
+}
+
+// splitTolerance splits the value into the colour and the tolerance
+func splitTolerance(s string) (string, string, error) {
+	c, tol, found := cutLast(s, toleranceSep)
+	if !found {
+		c, tol, found = cutLast(s, toleranceSepASCII)
+	}
+
+	if !found {
+		return "", "", errors.New("no tolerance given:" +
+			" the colour must be followed by " + toleranceSep +
+			" (or " + toleranceSepASCII + ") and the tolerance")
+	}
+
+	return c, strings.TrimSpace(tol), nil
+}
+
+// ColourTolerance is used to set a ColourMatch value giving a target colour
+// and a tolerance. The value is a colour (in any of the forms accepted by the
+// RGB setter) followed by ± (or +/-) and a tolerance. The tolerance is either
+// a Delta E value (optionally prefixed with "de:") or "ch:" followed by
+// per-channel tolerances.
+//
+//nolint:misspell
+type ColourTolerance struct {
+	psetter.ValueReqMandatory
+
+	Value    *ColourMatch
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// Formula gives the formula used to measure the difference between
+	// colours when the tolerance is given as a Delta E value. If it is not
+	// set the CIEDE2000 formula is used.
+	Formula DeltaEFormula
+}
+
+// parser returns the colourParser for this setter
+func (s ColourTolerance) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// colour and the tolerance and, if they are both valid, sets the Value.
+func (s ColourTolerance) SetWithVal(_ string, paramVal string) error {
+	cStr, tolStr, err := splitTolerance(paramVal)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.parser().parseRGBA(cStr)
+	if err != nil {
+		return err
+	}
+
+	cm := ColourMatch{Target: c, Formula: s.Formula}
+
+	lcTol := strings.ToLower(tolStr)
+	if strings.HasPrefix(lcTol, tolChannelPrefix) {
+		cm.ByChannel = true
+
+		cm.ChannelTol, cm.CheckAlpha, err = parseChannelTol(
+			tolStr[len(tolChannelPrefix):])
+	} else {
+		cm.MaxDeltaE, err = parseDeltaETol(
+			strings.TrimPrefix(lcTol, tolDeltaEPrefix))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*s.Value = cm
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s ColourTolerance) AllowedValues() string {
+	return "a colour followed by " + toleranceSep +
+		" (or " + toleranceSepASCII + ") and a tolerance." +
+		" The tolerance is either the largest" +
+		" perceptual difference (Delta E, measured using the " +
+		s.Formula.String() + " formula) between the colour and" +
+		" a matching colour, optionally preceded by " + tolDeltaEPrefix +
+		", or else " + tolChannelPrefix +
+		" followed by the largest difference in each channel." +
+		" The channel tolerance is either a single value" +
+		" (for the red, green and blue channels)" +
+		" or three values (red, green and blue)" +
+		" or four values (red, green, blue and alpha)," +
+		" separated by commas. If no alpha tolerance is given" +
+		" the alpha channel is ignored" +
+		"\n\n" +
+		"The colour is given as follows. " + s.parser().allowedValues()
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s ColourTolerance) ValDescribe() string {
+	return "colour" + toleranceSep + "tolerance"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s ColourTolerance) CurrentValue() string {
+	return s.Value.String()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder or
+// Formula is invalid.
+func (s ColourTolerance) CheckSetter(name string) {
+	intro := name + ": coloursetter.ColourTolerance Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " ColourTolerance.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " ColourTolerance.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " ColourTolerance.ChannelOrder: " + err.Error())
+	}
+
+	if err := s.Formula.Check(); err != nil {
+		panic(intro + " ColourTolerance.Formula: " + err.Error())
+	}
+}
@@ -0,0 +1,163 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestColourToleranceSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      ColourTolerance
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("Delta E"),
+			v:      "red±5",
+			expVal: "#ff0000ff±de:5 (CIEDE2000)",
+		},
+		{
+			ID:     testhelper.MkID("Delta E - ASCII, prefix, CIE76"),
+			s:      ColourTolerance{Formula: CIE76},
+			v:      "#00f +/- dE:2.5",
+			expVal: "#0000ffff±de:2.5 (CIE76)",
+		},
+		{
+			ID:     testhelper.MkID("channel - single value"),
+			v:      "rgb:ff/80/00±ch:10",
+			expVal: "#ff8000ff±ch:10,10,10",
+		},
+		{
+			ID:     testhelper.MkID("channel - four values"),
+			v:      "#ff000080±CH:1,2,3,0x10",
+			expVal: "#ff000080±ch:1,2,3,16",
+		},
+		{
+			ID: testhelper.MkID("no tolerance"),
+			ExpErr: testhelper.MkExpErr("no tolerance given:" +
+				" the colour must be followed by ± (or +/-) and the tolerance"),
+			v: "red",
+		},
+		{
+			ID:     testhelper.MkID("bad colour"),
+			ExpErr: testhelper.MkExpErr(`"nonesuch"`),
+			v:      "nonesuch±5",
+		},
+		{
+			ID: testhelper.MkID("bad Delta E"),
+			ExpErr: testhelper.MkExpErr(`bad Delta E tolerance "-1":` +
+				" it must be a finite value, greater than or equal to zero"),
+			v: "red±-1",
+		},
+		{
+			ID:     testhelper.MkID("Delta E not a number"),
+			ExpErr: testhelper.MkExpErr(`bad Delta E tolerance "x": not a number`),
+			v:      "red±x",
+		},
+		{
+			ID: testhelper.MkID("bad channel count"),
+			ExpErr: testhelper.MkExpErr(`bad channel tolerance "1,2":` +
+				" 1, 3 or 4 values expected, 2 found"),
+			v: "red±ch:1,2",
+		},
+		{
+			ID: testhelper.MkID("bad channel value"),
+			ExpErr: testhelper.MkExpErr(`bad channel tolerance "1,256,3":` +
+				` part 2 ("256") must be a number from 0 to 255`),
+			v: "red±ch:1,256,3",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v ColourMatch
+
+		s := tc.s
+		s.Value = &v
+		err := s.SetWithVal("", tc.v)
+
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "value",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestColourMatchMatches(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff} //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		cm       ColourMatch
+		c        color.Color //nolint:misspell
+		expMatch bool
+	}{
+		{
+			ID:       testhelper.MkID("Delta E - exact"),
+			cm:       ColourMatch{Target: red},
+			c:        red,
+			expMatch: true,
+		},
+		{
+			ID:       testhelper.MkID("Delta E - close"),
+			cm:       ColourMatch{Target: red, MaxDeltaE: 5},
+			c:        color.RGBA{R: 0xf8, G: 0x08, A: 0xff}, //nolint:misspell
+			expMatch: true,
+		},
+		{
+			ID:       testhelper.MkID("Delta E - too far"),
+			cm:       ColourMatch{Target: red, MaxDeltaE: 5},
+			c:        color.RGBA{R: 0xc0, A: 0xff}, //nolint:misspell
+			expMatch: false,
+		},
+		{
+			ID:       testhelper.MkID("Delta E - other colour model"),
+			cm:       ColourMatch{Target: red, MaxDeltaE: 1},
+			c:        color.NRGBA64{R: 0xffff, A: 0xffff}, //nolint:misspell
+			expMatch: true,
+		},
+		{
+			ID: testhelper.MkID("channel - within"),
+			cm: ColourMatch{
+				Target:     red,
+				ByChannel:  true,
+				ChannelTol: [4]uint8{0x10, 0x10, 0x10},
+			},
+			c:        color.NRGBA{R: 0xf0, G: 0x10, A: 0x01}, //nolint:misspell
+			expMatch: true,
+		},
+		{
+			ID: testhelper.MkID("channel - green too far"),
+			cm: ColourMatch{
+				Target:     red,
+				ByChannel:  true,
+				ChannelTol: [4]uint8{0x10, 0x10, 0x10},
+			},
+			c:        color.RGBA{R: 0xf0, G: 0x11, A: 0xff}, //nolint:misspell
+			expMatch: false,
+		},
+		{
+			ID: testhelper.MkID("channel - alpha checked"),
+			cm: ColourMatch{
+				Target:     red,
+				ByChannel:  true,
+				ChannelTol: [4]uint8{0x10, 0x10, 0x10, 0x10},
+				CheckAlpha: true,
+			},
+			c:        color.NRGBA{R: 0xf0, G: 0x10, A: 0x01}, //nolint:misspell
+			expMatch: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		if m := tc.cm.Matches(tc.c); m != tc.expMatch {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected: %t", tc.expMatch)
+			t.Logf("\t:      got: %t", m)
+			t.Error("\t: unexpected match result")
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+)
+
+// ContrastMetric identifies the algorithm used to measure the contrast
+// between a foreground (text) colour and a background colour
+type ContrastMetric int
+
+// These are the supported contrast metrics
+const (
+	// WCAG2 is the contrast ratio defined in the Web Content Accessibility
+	// Guidelines 2.x, from 1 (no contrast) to 21 (black on white)
+	WCAG2 ContrastMetric = iota
+	// APCA is the Accessible Perceptual Contrast Algorithm (the WCAG 3
+	// candidate) giving the lightness contrast, Lc, from about -108 to
+	// 106. It depends on which colour is the text and which the
+	// background; the sign gives the polarity (negative for light text on
+	// a dark background).
+	APCA
+)
+
+// String returns the name of the contrast metric
+func (m ContrastMetric) String() string {
+	switch m {
+	case WCAG2:
+		return "WCAG2"
+	case APCA:
+		return "APCA"
+	}
+
+	return fmt.Sprintf("ContrastMetric(%d)", int(m))
+}
+
+// Check returns a non-nil error if the ContrastMetric is not one of the
+// supported values.
+func (m ContrastMetric) Check() error {
+	if m < WCAG2 || m > APCA {
+		return fmt.Errorf("%d is not a valid ContrastMetric", int(m))
+	}
+
+	return nil
+}
+
+// Contrast returns the contrast between the text colour and the background
+// colour using the metric. The alpha channels are ignored.
+func (m ContrastMetric) Contrast(text, bg color.RGBA) float64 { //nolint:misspell
+	if m == APCA {
+		return APCAContrast(text, bg)
+	}
+
+	return WCAGContrastRatio(text, bg)
+}
+
+// Strength returns a value which increases with the contrast between the
+// text colour and the background colour regardless of polarity. This is
+// the WCAG 2 contrast ratio or the absolute value of the APCA Lc.
+func (m ContrastMetric) Strength(text, bg color.RGBA) float64 { //nolint:misspell
+	return math.Abs(m.Contrast(text, bg))
+}
+
+// RelativeLuminance returns the WCAG 2 relative luminance of the colour,
+// from 0.0 (black) to 1.0 (white). The alpha channel is ignored.
+func RelativeLuminance(c color.RGBA) float64 { //nolint:misspell
+	r, g, b := linearRGB(c)
+
+	return 0.2126*r + 0.7152*g + 0.0722*b //nolint:mnd
+}
+
+// WCAGContrastRatio returns the WCAG 2 contrast ratio between the two
+// colours, from 1.0 to 21.0. It does not matter which colour is given
+// first. The alpha channels are ignored.
+func WCAGContrastRatio(c1, c2 color.RGBA) float64 { //nolint:misspell
+	const flare = 0.05
+
+	l1, l2 := RelativeLuminance(c1), RelativeLuminance(c2)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+
+	return (l1 + flare) / (l2 + flare)
+}
+
+// These are the constants of the APCA algorithm (version 0.0.98G-4g, as
+// used by the apca-w3 reference implementation)
+const (
+	apcaMainTRC = 2.4
+
+	apcaRedCoeff   = 0.2126729
+	apcaGreenCoeff = 0.7151522
+	apcaBlueCoeff  = 0.0721750
+
+	apcaNormBG  = 0.56
+	apcaNormTXT = 0.57
+	apcaRevTXT  = 0.62
+	apcaRevBG   = 0.65
+
+	apcaBlkThrs = 0.022
+	apcaBlkClmp = 1.414
+
+	apcaScaleBoW    = 1.14
+	apcaScaleWoB    = 1.14
+	apcaLoBoWOffset = 0.027
+	apcaLoWoBOffset = 0.027
+	apcaDeltaYMin   = 0.0005
+	apcaLoClip      = 0.1
+)
+
+// apcaY returns the APCA screen luminance of the colour
+func apcaY(c color.RGBA) float64 { //nolint:misspell
+	channel := func(v uint8) float64 {
+		return math.Pow(float64(v)/math.MaxUint8, apcaMainTRC)
+	}
+
+	return apcaRedCoeff*channel(c.R) +
+		apcaGreenCoeff*channel(c.G) +
+		apcaBlueCoeff*channel(c.B)
+}
+
+// apcaSoftClamp applies the APCA soft clamp to very dark luminance values
+func apcaSoftClamp(y float64) float64 {
+	if y > apcaBlkThrs {
+		return y
+	}
+
+	return y + math.Pow(apcaBlkThrs-y, apcaBlkClmp)
+}
+
+// APCAContrast returns the APCA lightness contrast (Lc) of the text colour
+// on the background colour. It is positive for dark text on a light
+// background and negative for light text on a dark background. An
+// absolute value of 75 or more is recommended for body text, 60 for
+// content text and 45 for large headings. The alpha channels are ignored.
+func APCAContrast(text, bg color.RGBA) float64 { //nolint:misspell
+	const scale = 100
+
+	textY := apcaSoftClamp(apcaY(text))
+	bgY := apcaSoftClamp(apcaY(bg))
+
+	if math.Abs(bgY-textY) < apcaDeltaYMin {
+		return 0
+	}
+
+	if bgY > textY { // dark text on a light background
+		sapc := (math.Pow(bgY, apcaNormBG) - math.Pow(textY, apcaNormTXT)) *
+			apcaScaleBoW
+		if sapc < apcaLoClip {
+			return 0
+		}
+
+		return (sapc - apcaLoBoWOffset) * scale
+	}
+
+	sapc := (math.Pow(bgY, apcaRevBG) - math.Pow(textY, apcaRevTXT)) *
+		apcaScaleWoB
+	if sapc > -apcaLoClip {
+		return 0
+	}
+
+	return (sapc + apcaLoWoBOffset) * scale
+}
+
+// checkMin returns a non-nil error if the contrast between the text
+// colour and the background colour is less than the minimum. The names are
+// used to describe the colours in the error message.
+func (m ContrastMetric) checkMin(textName, bgName string,
+	text, bg color.RGBA, minContrast float64, //nolint:misspell
+) error {
+	if c := m.Strength(text, bg); c < minContrast {
+		return fmt.Errorf("the contrast between %q and %q is too low:"+
+			" %s %.2f (minimum: %g)",
+			textName, bgName, m, c, minContrast)
+	}
+
+	return nil
+}
+
+// bestContrast returns the candidate colour giving the strongest contrast
+// with the given colour using the metric. If textIsCandidate is true the
+// candidates are taken as the text colour and the given colour as the
+// background, otherwise the other way round. Where candidates give the
+// same contrast the first is chosen. The candidates must not be empty.
+func (m ContrastMetric) bestContrast(c color.RGBA, //nolint:misspell
+	candidates []color.RGBA, textIsCandidate bool, //nolint:misspell
+) color.RGBA { //nolint:misspell
+	best := candidates[0]
+	bestStrength := -1.0
+
+	for _, cand := range candidates {
+		text, bg := cand, c
+		if !textIsCandidate {
+			text, bg = c, cand
+		}
+
+		if s := m.Strength(text, bg); s > bestStrength {
+			best, bestStrength = cand, s
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,269 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestWCAGContrastRatio(t *testing.T) {
+	const epsilon = 0.005
+
+	testCases := []struct {
+		testhelper.ID
+		c1, c2 string
+		expVal float64
+	}{
+		{ID: testhelper.MkID("black on white"), c1: "000", c2: "fff", expVal: 21},
+		{ID: testhelper.MkID("white on black"), c1: "fff", c2: "000", expVal: 21},
+		{ID: testhelper.MkID("same colour"), c1: "123456", c2: "123456", expVal: 1},
+		{ID: testhelper.MkID("grey on white"), c1: "777", c2: "fff", expVal: 4.48},
+		{ID: testhelper.MkID("navy on white"), c1: "000080", c2: "fff", expVal: 16.01},
+	}
+
+	for _, tc := range testCases {
+		c1, err1 := ChannelOrderRGB.ParseHex(tc.c1)
+		c2, err2 := ChannelOrderRGB.ParseHex(tc.c2)
+
+		if err1 != nil || err2 != nil {
+			t.Fatal(tc.IDStr(), ": bad colour: ", err1, err2)
+		}
+
+		if v := WCAGContrastRatio(c1, c2); math.Abs(v-tc.expVal) > epsilon {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected: %g", tc.expVal)
+			t.Logf("\t:      got: %g", v)
+			t.Error("\t: unexpected contrast ratio")
+		}
+	}
+}
+
+func TestAPCAContrast(t *testing.T) {
+	const (
+		epsilon = 1e-9
+		fName   = "testdata/APCA/reference.txt"
+	)
+
+	content, err := os.ReadFile(fName)
+	if err != nil {
+		t.Fatal("cannot read the APCA test vectors: ", err)
+	}
+
+	count := 0
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		id := fName + ":" + strconv.Itoa(i+1)
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			t.Fatalf("%s: 3 fields expected, %d found", id, len(fields))
+		}
+
+		text, err1 := ChannelOrderRGB.ParseHex(fields[0])
+		bg, err2 := ChannelOrderRGB.ParseHex(fields[1])
+		expVal, err3 := strconv.ParseFloat(fields[2], 64)
+
+		if err1 != nil || err2 != nil || err3 != nil {
+			t.Fatalf("%s: bad test vector: %q", id, line)
+		}
+
+		count++
+
+		if v := APCAContrast(text, bg); math.Abs(v-expVal) > epsilon {
+			t.Log(id)
+			t.Logf("\t: %s on %s", fields[0], fields[1])
+			t.Logf("\t: expected: %.15g", expVal)
+			t.Logf("\t:      got: %.15g", v)
+			t.Error("\t: unexpected APCA contrast")
+		}
+	}
+
+	if count == 0 {
+		t.Error("no APCA test vectors were found in " + fName)
+	}
+
+	grey := color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff} //nolint:misspell
+	if v := APCAContrast(grey, grey); v != 0 {
+		t.Errorf("the APCA contrast of a colour with itself should be 0, not %g",
+			v)
+	}
+}
+
+func TestRGBPairAuto(t *testing.T) {
+	grey := color.RGBA{R: 0x88, G: 0x88, B: 0x88, A: 0xff} //nolint:misspell
+	red := color.RGBA{R: 0xff, A: 0xff}                    //nolint:misspell
+	cyan := color.RGBA{G: 0xff, B: 0xff, A: 0xff}          //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v          string
+		metric     ContrastMetric
+		candidates []color.RGBA //nolint:misspell
+		families   colour.Families
+		minCon     float64
+		expVal     string
+	}{
+		{
+			ID:     testhelper.MkID("auto text on navy"),
+			v:      "auto;navy",
+			expVal: "auto(#ffffffff);#000080ff",
+		},
+		{
+			ID:     testhelper.MkID("auto background, mixed case"),
+			v:      "#ffff80;AUTO",
+			expVal: "#ffff80ff;auto(#000000ff)",
+		},
+		{
+			ID:     testhelper.MkID("mid grey, WCAG2"),
+			v:      "auto;#888",
+			expVal: "auto(#000000ff);#888888ff",
+		},
+		{
+			ID:     testhelper.MkID("mid grey, APCA"),
+			v:      "auto;#888",
+			metric: APCA,
+			expVal: "auto(#ffffffff);#888888ff",
+		},
+		{
+			ID:         testhelper.MkID("from candidates"),
+			v:          "navy;auto",
+			candidates: []color.RGBA{red, cyan, grey}, //nolint:misspell
+			expVal:     "#000080ff;auto(#00ffffff)",
+		},
+		{
+			ID:       testhelper.MkID("from families"),
+			v:        "navy;auto",
+			families: colour.Families{colour.PantoneColours},
+			expVal:   "#000080ff;auto(#f4f5f0ff)",
+		},
+		{
+			ID:     testhelper.MkID("neither auto"),
+			v:      "navy;white",
+			expVal: "#000080ff;#ffffffff",
+		},
+		{
+			ID:     testhelper.MkID("minimum contrast, WCAG2"),
+			v:      "#767676;white",
+			minCon: 4.5,
+			expVal: "#767676ff;#ffffffff",
+		},
+		{
+			ID: testhelper.MkID("minimum contrast not met, WCAG2"),
+			ExpErr: testhelper.MkExpErr(
+				`the contrast between "#777" and "white" is too low:` +
+					" WCAG2 4.48 (minimum: 4.5)"),
+			v:      "#777 ; white",
+			minCon: 4.5,
+		},
+		{
+			ID: testhelper.MkID("minimum contrast not met, APCA"),
+			ExpErr: testhelper.MkExpErr(
+				`the contrast between "#888" and "white" is too low:` +
+					" APCA 63.06 (minimum: 75)"),
+			v:      "#888;white",
+			metric: APCA,
+			minCon: 75,
+		},
+		{
+			ID:     testhelper.MkID("minimum contrast, APCA, light on dark"),
+			v:      "white;#333",
+			metric: APCA,
+			minCon: 75,
+			expVal: "#ffffffff;#333333ff",
+		},
+		{
+			ID: testhelper.MkID("both auto"),
+			ExpErr: testhelper.MkExpErr("both colours are auto" +
+				" - at most one of the colours may be chosen automatically"),
+			v: "auto;auto",
+		},
+		{
+			ID:     testhelper.MkID("bad colour"),
+			ExpErr: testhelper.MkExpErr(`"nonesuch"`),
+			v:      "auto;nonesuch",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v1, v2 color.RGBA //nolint:misspell
+
+		s := &RGBPair{
+			Value1:         &v1,
+			Value2:         &v2,
+			ShowHex:        true,
+			Contrast:       tc.metric,
+			MinContrast:    tc.minCon,
+			AutoCandidates: tc.candidates,
+			AutoFamilies:   tc.families,
+		}
+
+		err := s.SetWithVal("", tc.v)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "value",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestRGBPairAutoChanged(t *testing.T) {
+	var v1, v2 color.RGBA //nolint:misspell
+
+	s := &RGBPair{Value1: &v1, Value2: &v2, ShowHex: true}
+
+	if err := s.SetWithVal("", "auto;black"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+
+	first, second := s.AutoChosen()
+	testhelper.DiffBool(t, "derived", "first", first, true)
+	testhelper.DiffBool(t, "derived", "second", second, false)
+	testhelper.DiffString(t, "derived", "value",
+		s.CurrentValue(), "auto(#ffffffff);#000000ff")
+
+	v1.R = 0x80
+
+	testhelper.DiffString(t, "changed after being derived", "value",
+		s.CurrentValue(), "#80ffffff;#000000ff")
+}
+
+func TestRGBPairAutoCVD(t *testing.T) {
+	t.Cleanup(ClearCVDSimulation)
+
+	var v1, v2 color.RGBA //nolint:misspell
+
+	cvd := NewCVDScope()
+	ps := param.NewSet(quietHelper{})
+
+	ps.Add("simulate-cvd", CVDSimulation{}, "simulate a CVD")
+	ps.Add("pair",
+		&RGBPair{Value1: &v1, Value2: &v2, ShowHex: true, CVD: cvd},
+		"a pair of colours")
+	cvd.AddFinalCheck(ps)
+
+	ps.Parse([]string{"-pair", "red;auto", "-simulate-cvd", "protanopia"})
+
+	if errs := ps.Errors(); len(errs) != 0 {
+		t.Fatal("unexpected errors: ", errs)
+	}
+
+	p, err := ps.GetParamByName("pair")
+	if err != nil {
+		t.Fatal("cannot find the pair parameter: ", err)
+	}
+
+	testhelper.DiffString(t, "simulated", "value",
+		p.Setter().CurrentValue(), "#6d5f00ff;auto(#000000ff)")
+}
@@ -0,0 +1,155 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+)
+
+// CVDType identifies a type of colour vision deficiency (colour blindness)
+type CVDType int
+
+// These are the supported colour vision deficiencies. Each is the complete
+// absence of one type of cone cell.
+const (
+	// Protanopia is the absence of the long-wavelength (red) cones
+	Protanopia CVDType = iota
+	// Deuteranopia is the absence of the medium-wavelength (green) cones
+	Deuteranopia
+	// Tritanopia is the absence of the short-wavelength (blue) cones
+	Tritanopia
+)
+
+// AllCVDTypes lists all the supported colour vision deficiencies
+var AllCVDTypes = []CVDType{Protanopia, Deuteranopia, Tritanopia}
+
+// String returns the name of the colour vision deficiency
+func (t CVDType) String() string {
+	switch t {
+	case Protanopia:
+		return "protanopia"
+	case Deuteranopia:
+		return "deuteranopia"
+	case Tritanopia:
+		return "tritanopia"
+	}
+
+	return fmt.Sprintf("CVDType(%d)", int(t))
+}
+
+// Check returns a non-nil error if the CVDType is not one of the supported
+// values.
+func (t CVDType) Check() error {
+	if t < Protanopia || t > Tritanopia {
+		return fmt.Errorf("%d is not a valid CVDType", int(t))
+	}
+
+	return nil
+}
+
+// cvdMatrices holds the matrices, from Machado, Oliveira and Fernandes
+// (2009) with a severity of 1.0, to be applied to linear RGB values to
+// simulate each colour vision deficiency
+var cvdMatrices = map[CVDType][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// SimulateCVD returns the colour as it would be seen by someone with the
+// given colour vision deficiency. It uses the model of Machado, Oliveira and
+// Fernandes (2009). The alpha channel is unchanged.
+func SimulateCVD(c color.RGBA, t CVDType) color.RGBA { //nolint:misspell
+	m, ok := cvdMatrices[t]
+	if !ok {
+		return c
+	}
+
+	r, g, b := linearRGB(c)
+
+	toSRGB := func(row [3]float64) uint8 {
+		v := row[0]*r + row[1]*g + row[2]*b
+
+		return clampUint8(linearToSRGB(max(0, min(1, v))) * math.MaxUint8)
+	}
+
+	return color.RGBA{ //nolint:misspell
+		R: toSRGB(m[0]),
+		G: toSRGB(m[1]),
+		B: toSRGB(m[2]),
+		A: c.A,
+	}
+}
+
+// cvdColour is a colour together with the text used to describe it in
+// error messages
+type cvdColour struct {
+	name string
+	c    color.RGBA //nolint:misspell
+}
+
+// namedCVDColours returns the named colours as cvdColours, described by
+// their names
+func namedCVDColours(ncs []colour.NamedColour) []cvdColour {
+	colours := make([]cvdColour, 0, len(ncs))
+	for _, nc := range ncs {
+		colours = append(colours, cvdColour{name: nc.Name(), c: nc.Colour()})
+	}
+
+	return colours
+}
+
+// checkCVDDistinct returns a non-nil error if any pair of the colours, as
+// seen by someone with any of the colour vision deficiencies, are closer
+// than the minimum (CIEDE2000) Delta E. Pairs of colours which are
+// identical are not reported.
+func checkCVDDistinct(colours []cvdColour, minDeltaE float64) error {
+	errs := []error{}
+
+	for i, c1 := range colours {
+		for _, c2 := range colours[i+1:] {
+			if c1.c == c2.c {
+				continue
+			}
+
+			for _, t := range AllCVDTypes {
+				dist := DeltaE2000(SimulateCVD(c1.c, t), SimulateCVD(c2.c, t))
+				if dist < minDeltaE {
+					errs = append(errs,
+						fmt.Errorf("the colours %q and %q are hard to tell apart"+
+							" with %s: ΔE %.1f (minimum: %g)",
+							c1.name, c2.name, t, dist, minDeltaE))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// cvdAllowedValues returns a description of the colour vision deficiency
+// check, if any, to be added to the AllowedValues text
+func cvdAllowedValues(which string, minDeltaE float64) string {
+	if minDeltaE <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(". As seen by someone with %s, %s or %s,"+
+		" %s must differ by at least %g (CIEDE2000 Delta E)",
+		Protanopia, Deuteranopia, Tritanopia, which, minDeltaE)
+}
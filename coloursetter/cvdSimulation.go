@@ -0,0 +1,227 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"strings"
+	"sync"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// cvdSimNone is the value given to the CVDSimulation setter to turn off
+// the simulation
+const cvdSimNone = "none"
+
+// cvdSim records the colour vision deficiency, if any, to be simulated
+var cvdSim struct {
+	mu     sync.RWMutex
+	active bool
+	t      CVDType
+}
+
+// SetCVDSimulation turns on the simulation of the given colour vision
+// deficiency. The colours registered with a CVDScope are transformed,
+// when the scope is applied, to show how they would be seen by someone
+// with the deficiency. This allows an application's colours to be
+// previewed without changing its configuration.
+func SetCVDSimulation(t CVDType) error {
+	if err := t.Check(); err != nil {
+		return err
+	}
+
+	cvdSim.mu.Lock()
+	defer cvdSim.mu.Unlock()
+
+	cvdSim.active = true
+	cvdSim.t = t
+
+	return nil
+}
+
+// ClearCVDSimulation turns off the simulation of colour vision deficiency
+func ClearCVDSimulation() {
+	cvdSim.mu.Lock()
+	defer cvdSim.mu.Unlock()
+
+	cvdSim.active = false
+}
+
+// CurrentCVDSimulation returns the colour vision deficiency being simulated
+// and true, or false if there is no simulation
+func CurrentCVDSimulation() (CVDType, bool) {
+	cvdSim.mu.RLock()
+	defer cvdSim.mu.RUnlock()
+
+	return cvdSim.t, cvdSim.active
+}
+
+// ApplyCVDSimulation returns the colour transformed by the current colour
+// vision deficiency simulation, if any. It can be used to apply the same
+// transformation as a CVDScope to colours which are not set by a colour
+// setter.
+func ApplyCVDSimulation(c color.RGBA) color.RGBA { //nolint:misspell
+	if t, ok := CurrentCVDSimulation(); ok {
+		return SimulateCVD(c, t)
+	}
+
+	return c
+}
+
+// simulateCVDNamed returns the NamedColour with its colour transformed to
+// simulate the colour vision deficiency. The name is unchanged.
+func simulateCVDNamed(nc colour.NamedColour, t CVDType) colour.NamedColour {
+	return colour.MakeNamedColour(nc.Name(), SimulateCVD(nc.Colour(), t))
+}
+
+// cvdTarget is a colour value registered with a CVDScope together with the
+// function which transforms it
+type cvdTarget struct {
+	value any
+	apply func(t CVDType)
+}
+
+// CVDScope collects the colour values to be transformed by the colour
+// vision deficiency simulation (see SetCVDSimulation). The RGB, RGBPair,
+// NamedColour, RGBMap, Palette, Scale and Harmony setters having this
+// CVDScope as their CVD register their Values with it when they are added
+// to the param set. Once all the parameters have been parsed the
+// simulation, if any, is applied to every registered value, whether it was
+// set by a parameter or is a default value, and so the order in which the
+// parameters are given does not matter.
+//
+// A typical use would be:
+//
+//	cvd := coloursetter.NewCVDScope()
+//
+//	ps.Add("simulate-cvd", coloursetter.CVDSimulation{}, ...)
+//	ps.Add("fg", coloursetter.RGB{Value: &fg, CVD: cvd}, ...)
+//	cvd.AddFinalCheck(ps)
+//
+// The final check must be added after those of any FamilyScope or
+// ColourRefs (so that the colours they set are transformed) and before any
+// other final checks which use the colours.
+type CVDScope struct {
+	targets []cvdTarget
+}
+
+// NewCVDScope returns a new, empty, CVDScope
+func NewCVDScope() *CVDScope {
+	return &CVDScope{}
+}
+
+// register records the colour value and the function which transforms
+// it. The value is the pointer to the setter's Value; a value already
+// registered is not registered again so that it is only transformed once.
+// It does nothing if the CVDScope is nil.
+func (cs *CVDScope) register(value any, apply func(t CVDType)) {
+	if cs == nil {
+		return
+	}
+
+	for _, tgt := range cs.targets {
+		if tgt.value == value {
+			return
+		}
+	}
+
+	cs.targets = append(cs.targets, cvdTarget{value: value, apply: apply})
+}
+
+// registerRGB registers the colour value
+func (cs *CVDScope) registerRGB(v *color.RGBA) { //nolint:misspell
+	cs.register(v, func(t CVDType) { *v = SimulateCVD(*v, t) })
+}
+
+// registerNamedColours registers the list of NamedColour values
+func (cs *CVDScope) registerNamedColours(v *[]colour.NamedColour) {
+	cs.register(v, func(t CVDType) {
+		for i, nc := range *v {
+			(*v)[i] = simulateCVDNamed(nc, t)
+		}
+	})
+}
+
+// Apply transforms every registered colour value to simulate the current
+// colour vision deficiency, if any. It should only be called once, after
+// all the parameters have been parsed; it need not be called if
+// AddFinalCheck has been used.
+func (cs *CVDScope) Apply() {
+	t, ok := CurrentCVDSimulation()
+	if !ok {
+		return
+	}
+
+	for _, tgt := range cs.targets {
+		tgt.apply(t)
+	}
+}
+
+// AddFinalCheck adds a final check to the param set which applies the
+// colour vision deficiency simulation, if any, to the registered colour
+// values once all the parameters have been parsed.
+func (cs *CVDScope) AddFinalCheck(ps *param.PSet) {
+	ps.AddFinalCheck(func() error {
+		cs.Apply()
+
+		return nil
+	})
+}
+
+// CVDSimulation is used to turn on (or off) the package-wide simulation of a
+// colour vision deficiency. The simulation is applied to the colours
+// registered with a CVDScope once all the parameters have been parsed. See
+// SetCVDSimulation and CVDScope.
+type CVDSimulation struct {
+	psetter.ValueReqMandatory
+}
+
+// SetWithVal (called with the value following the parameter) sets the
+// colour vision deficiency to be simulated
+func (s CVDSimulation) SetWithVal(_ string, paramVal string) error {
+	val := strings.ToLower(strings.TrimSpace(paramVal))
+	if val == cvdSimNone {
+		ClearCVDSimulation()
+		return nil
+	}
+
+	for _, t := range AllCVDTypes {
+		if val == t.String() {
+			return SetCVDSimulation(t)
+		}
+	}
+
+	return fmt.Errorf("unknown colour vision deficiency: %q", paramVal)
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s CVDSimulation) AllowedValues() string {
+	return "one of " +
+		Protanopia.String() + ", " +
+		Deuteranopia.String() + " or " +
+		Tritanopia.String() +
+		" to simulate that colour vision deficiency" +
+		" in the colours used" +
+		", or " + cvdSimNone + " to turn off the simulation"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s CVDSimulation) ValDescribe() string {
+	return "deficiency"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s CVDSimulation) CurrentValue() string {
+	if t, ok := CurrentCVDSimulation(); ok {
+		return t.String()
+	}
+
+	return cvdSimNone
+}
+
+// CheckSetter does nothing, the CVDSimulation setter has no values to
+// check
+func (s CVDSimulation) CheckSetter(_ string) {}
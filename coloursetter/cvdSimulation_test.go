@@ -0,0 +1,189 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestCVDSimulationSetter(t *testing.T) {
+	t.Cleanup(ClearCVDSimulation)
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("deuteranopia"),
+			v:      "Deuteranopia",
+			expVal: "deuteranopia",
+		},
+		{
+			ID:     testhelper.MkID("none"),
+			v:      " none ",
+			expVal: "none",
+		},
+		{
+			ID:     testhelper.MkID("tritanopia"),
+			v:      "tritanopia",
+			expVal: "tritanopia",
+		},
+		{
+			ID: testhelper.MkID("bad value - unchanged"),
+			ExpErr: testhelper.MkExpErr(
+				`unknown colour vision deficiency: "daltonism"`),
+			v:      "daltonism",
+			expVal: "tritanopia",
+		},
+	}
+
+	s := CVDSimulation{}
+
+	for _, tc := range testCases {
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+		testhelper.DiffString(t, tc.IDStr(), "current value",
+			s.CurrentValue(), tc.expVal)
+	}
+}
+
+func TestCVDSimulationApplied(t *testing.T) {
+	t.Cleanup(ClearCVDSimulation)
+
+	red := color.RGBA{R: 0xff, A: 0xff}             //nolint:misspell
+	simRed := color.RGBA{R: 0x6d, G: 0x5f, A: 0xff} //nolint:misspell
+
+	if err := SetCVDSimulation(Protanopia); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+
+	cvd := NewCVDScope()
+
+	var (
+		rgbVal         color.RGBA //nolint:misspell
+		pairV1, pairV2 color.RGBA //nolint:misspell
+		namedColourVal colour.NamedColour
+		rgbListVal     []color.RGBA //nolint:misspell
+		namedListVal   []colour.NamedColour
+		palVal         color.Palette         //nolint:misspell
+		mapVal         map[string]color.RGBA //nolint:misspell
+		scaleVal       ColourScale
+		harmonyVal     []colour.NamedColour
+	)
+
+	setters := []struct {
+		name string
+		s    param.Setter
+		v    string
+	}{
+		{"RGB", RGB{Value: &rgbVal, CVD: cvd}, "red"},
+		{
+			"RGBPair",
+			&RGBPair{Value1: &pairV1, Value2: &pairV2, CVD: cvd},
+			"red;#fff",
+		},
+		{"NamedColour", NamedColour{Value: &namedColourVal, CVD: cvd}, "red"},
+		{"RGBList", RGBList{Value: &rgbListVal, CVD: cvd}, "red"},
+		{
+			"NamedColourList",
+			NamedColourList{Value: &namedListVal, CVD: cvd},
+			"red",
+		},
+		{"Palette", Palette{Value: &palVal, CVD: cvd}, "red"},
+		{"RGBMap", RGBMap{Value: &mapVal, CVD: cvd}, "r=red"},
+		{"Scale", Scale{Value: &scaleVal, CVD: cvd}, "red:3"},
+		{"Harmony", Harmony{Value: &harmonyVal, CVD: cvd}, "complementary(red)"},
+	}
+
+	for _, s := range setters {
+		s.s.CheckSetter(s.name)
+
+		if err := s.s.SetWithVal(s.name, s.v); err != nil {
+			t.Fatal(s.name+": unexpected error: ", err)
+		}
+	}
+
+	testhelper.DiffString(t, "RGB", "value before the scope is applied",
+		ChannelOrderRGBA.Hex(rgbVal), ChannelOrderRGBA.Hex(red))
+
+	cvd.Apply()
+	ClearCVDSimulation()
+
+	testhelper.DiffString(t, "RGB", "value",
+		ChannelOrderRGBA.Hex(rgbVal), ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffString(t, "RGBPair", "value 1",
+		ChannelOrderRGBA.Hex(pairV1), ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffString(t, "RGBPair", "value 2",
+		ChannelOrderRGBA.Hex(pairV2), "#ffffffff")
+	testhelper.DiffString(t, "NamedColour", "name",
+		namedColourVal.Name(), "red")
+	testhelper.DiffString(t, "NamedColour", "value",
+		ChannelOrderRGBA.Hex(namedColourVal.Colour()),
+		ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffString(t, "RGBList", "value",
+		ChannelOrderRGBA.Hex(rgbListVal[0]), ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffString(t, "NamedColourList", "value",
+		ChannelOrderRGBA.Hex(namedListVal[0].Colour()),
+		ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffString(t, "Palette", "value",
+		ChannelOrderRGBA.Hex(palVal[0].(color.RGBA)), //nolint:misspell,forcetypeassert
+		ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffString(t, "RGBMap", "value",
+		ChannelOrderRGBA.Hex(mapVal["r"]), ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffString(t, "Scale", "base",
+		ChannelOrderRGBA.Hex(scaleVal.Base), ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffBool(t, "Scale", "base in the scale",
+		slices.Contains(scaleVal.Colours, simRed), true)
+	testhelper.DiffString(t, "Harmony", "base",
+		ChannelOrderRGBA.Hex(harmonyVal[0].Colour()),
+		ChannelOrderRGBA.Hex(simRed))
+	testhelper.DiffString(t, "ApplyCVDSimulation", "cleared",
+		ChannelOrderRGBA.Hex(ApplyCVDSimulation(red)),
+		ChannelOrderRGBA.Hex(red))
+}
+
+func TestCVDScopeParamOrder(t *testing.T) {
+	t.Cleanup(ClearCVDSimulation)
+
+	simRed := color.RGBA{R: 0x6d, G: 0x5f, A: 0xff} //nolint:misspell
+
+	for _, args := range [][]string{
+		{"-fg", "red", "-simulate-cvd", "protanopia"},
+		{"-simulate-cvd", "protanopia", "-fg", "red"},
+	} {
+		ClearCVDSimulation()
+
+		var (
+			fg  color.RGBA                     //nolint:misspell
+			bg  = color.RGBA{R: 0xff, A: 0xff} //nolint:misspell
+			cvd = NewCVDScope()
+			ps  = param.NewSet(quietHelper{})
+		)
+
+		ps.Add("simulate-cvd", CVDSimulation{}, "simulate a CVD")
+		ps.Add("fg", RGB{Value: &fg, CVD: cvd}, "the text colour")
+		ps.Add("bg", RGB{Value: &bg, CVD: cvd}, "the background colour")
+		cvd.AddFinalCheck(ps)
+
+		ps.Parse(args)
+
+		for name, errs := range ps.Errors() {
+			for _, err := range errs {
+				t.Errorf("unexpected error: %s: %s", name, err)
+			}
+		}
+
+		id := strings.Join(args, " ")
+		testhelper.DiffString(t, id, "fg",
+			ChannelOrderRGBA.Hex(fg), ChannelOrderRGBA.Hex(simRed))
+		testhelper.DiffString(t, id, "bg (the default)",
+			ChannelOrderRGBA.Hex(bg), ChannelOrderRGBA.Hex(simRed))
+	}
+}
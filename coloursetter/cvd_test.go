@@ -0,0 +1,124 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSimulateCVD(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		c      string
+		cvd    CVDType
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("red - protanopia"),
+			c:      "#ff0000",
+			cvd:    Protanopia,
+			expVal: "#6d5f00ff",
+		},
+		{
+			ID:     testhelper.MkID("lime - deuteranopia"),
+			c:      "#00ff00",
+			cvd:    Deuteranopia,
+			expVal: "#efd63aff",
+		},
+		{
+			ID:     testhelper.MkID("blue - tritanopia, alpha kept"),
+			c:      "#0000ff80",
+			cvd:    Tritanopia,
+			expVal: "#006b9680",
+		},
+		{
+			ID:     testhelper.MkID("grey - unchanged"),
+			c:      "#808080",
+			cvd:    Deuteranopia,
+			expVal: "#808080ff",
+		},
+		{
+			ID:     testhelper.MkID("bad CVD type - unchanged"),
+			c:      "#ff0000",
+			cvd:    CVDType(9),
+			expVal: "#ff0000ff",
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := ChannelOrderRGBA.ParseHex(tc.c)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": bad test colour: ", err)
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "simulated colour",
+			ChannelOrderRGBA.Hex(SimulateCVD(c, tc.cvd)), tc.expVal)
+	}
+}
+
+func TestCVDDistinct(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s setWithValer
+		v string
+	}{
+		{
+			ID: testhelper.MkID("pair - red;green"),
+			ExpErr: testhelper.MkExpErr(`the colours "red" and "green"` +
+				" are hard to tell apart with protanopia: ΔE 7.5 (minimum: 10)"),
+			s: pairSetter(10),
+			v: "red;green",
+		},
+		{
+			ID: testhelper.MkID("pair - red;green - low minimum"),
+			s:  pairSetter(5),
+			v:  "red;green",
+		},
+		{
+			ID: testhelper.MkID("pair - orange;blue"),
+			s:  pairSetter(10),
+			v:  "#d55e00;#0072b2",
+		},
+		{
+			ID: testhelper.MkID("palette - several problems"),
+			ExpErr: testhelper.MkExpErr(
+				`the colours "red" and "green" are hard to tell apart`+
+					" with protanopia",
+				`the colours "#777" and "#7a7a7a" are hard to tell apart`+
+					" with tritanopia"),
+			s: paletteSetter(10),
+			v: "red,green,#777,#7a7a7a",
+		},
+		{
+			ID: testhelper.MkID("palette - good"),
+			s:  paletteSetter(10),
+			v:  "black,white,#d55e00,#0072b2",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := tc.s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+// setWithValer is the part of the setter interface used by TestCVDDistinct
+type setWithValer interface {
+	SetWithVal(string, string) error
+}
+
+// pairSetter returns an RGBPair setter with the given CVDMinDeltaE
+func pairSetter(minDeltaE float64) *RGBPair {
+	var v1, v2 color.RGBA //nolint:misspell
+
+	return &RGBPair{Value1: &v1, Value2: &v2, CVDMinDeltaE: minDeltaE}
+}
+
+// paletteSetter returns a Palette setter with the given CVDMinDeltaE
+func paletteSetter(minDeltaE float64) Palette {
+	var v color.Palette //nolint:misspell
+
+	return Palette{Value: &v, CVDMinDeltaE: minDeltaE}
+}
@@ -0,0 +1,213 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+)
+
+// DeltaEFormula identifies the formula used to measure the perceptual
+// difference (Delta E) between two colours
+type DeltaEFormula int
+
+// These are the supported Delta E formulae
+const (
+	// CIEDE2000 is the CIE 2000 formula, the most perceptually uniform
+	CIEDE2000 DeltaEFormula = iota
+	// CIE76 is the Euclidean distance in the L*a*b* colour space
+	CIE76
+	// CIE94 is the CIE 1994 formula using the graphic arts weights
+	CIE94
+)
+
+// String returns the name of the formula
+func (f DeltaEFormula) String() string {
+	switch f {
+	case CIEDE2000:
+		return "CIEDE2000"
+	case CIE76:
+		return "CIE76"
+	case CIE94:
+		return "CIE94"
+	}
+
+	return fmt.Sprintf("DeltaEFormula(%d)", int(f))
+}
+
+// Check returns a non-nil error if the DeltaEFormula is not one of the
+// supported values.
+func (f DeltaEFormula) Check() error {
+	if f < CIEDE2000 || f > CIE94 {
+		return fmt.Errorf("%d is not a valid DeltaEFormula", int(f))
+	}
+
+	return nil
+}
+
+// DeltaE returns the difference between the two colours using the
+// formula. The alpha channels are ignored. Note that the CIE94 formula is not
+// symmetric, the first colour is taken as the reference.
+func (f DeltaEFormula) DeltaE(ref, sample color.RGBA) float64 { //nolint:misspell
+	return f.labDist(MakeLab(ref), MakeLab(sample))
+}
+
+// DeltaE76 returns the CIE76 difference between the two colours. This is
+// the Euclidean distance between the colours in the L*a*b* colour space. The
+// alpha channels are ignored.
+func DeltaE76(c1, c2 color.RGBA) float64 { //nolint:misspell
+	return CIE76.DeltaE(c1, c2)
+}
+
+// DeltaE94 returns the CIE94 difference between the two colours using the
+// graphic arts weights. The first colour is taken as the reference. The
+// alpha channels are ignored.
+func DeltaE94(ref, sample color.RGBA) float64 { //nolint:misspell
+	return CIE94.DeltaE(ref, sample)
+}
+
+// DeltaE2000 returns the CIEDE2000 difference between the two colours. The
+// alpha channels are ignored.
+func DeltaE2000(c1, c2 color.RGBA) float64 { //nolint:misspell
+	return CIEDE2000.DeltaE(c1, c2)
+}
+
+// labDist returns the difference between the two Lab colours using the
+// formula. Note that the CIE94 formula is not symmetric, the first colour
+// is taken as the reference.
+func (f DeltaEFormula) labDist(ref, sample Lab) float64 {
+	switch f {
+	case CIE76:
+		return deltaE76(ref, sample)
+	case CIE94:
+		return deltaE94(ref, sample)
+	default:
+		return deltaE2000(ref, sample)
+	}
+}
+
+// deltaE76 returns the CIE76 colour difference
+func deltaE76(c1, c2 Lab) float64 {
+	dL := c1.L - c2.L
+	da := c1.A - c2.A
+	db := c1.B - c2.B
+
+	return math.Sqrt(dL*dL + da*da + db*db)
+}
+
+// de94K1 is the CIE94 graphic arts chroma weighting factor, K1
+const de94K1 = 0.045
+
+// deltaE94 returns the CIE94 colour difference using the graphic arts
+// weights (kL = 1, K1 = 0.045, K2 = 0.015)
+func deltaE94(ref, sample Lab) float64 {
+	const (
+		k1 = de94K1
+		k2 = 0.015
+	)
+
+	dL := ref.L - sample.L
+	c1 := math.Hypot(ref.A, ref.B)
+	c2 := math.Hypot(sample.A, sample.B)
+	dC := c1 - c2
+	da := ref.A - sample.A
+	db := ref.B - sample.B
+	dH2 := max(0, da*da+db*db-dC*dC)
+
+	sC := 1 + k1*c1
+	sH := 1 + k2*c1
+
+	return math.Sqrt(dL*dL + (dC/sC)*(dC/sC) + dH2/(sH*sH))
+}
+
+// deltaE2000 returns the CIEDE2000 colour difference (with kL = kC = kH
+// = 1) as given by Sharma, Wu and Dalal (2005)
+//
+//nolint:mnd
+func deltaE2000(c1, c2 Lab) float64 {
+	const pow25To7 = 6103515625.0 // 25^7
+
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	deg := func(rad float64) float64 { return rad * 180 / math.Pi }
+
+	cBar := (math.Hypot(c1.A, c1.B) + math.Hypot(c2.A, c2.B)) / 2
+	cBar7 := math.Pow(cBar, 7)
+	g := 0.5 * (1 - math.Sqrt(cBar7/(cBar7+pow25To7)))
+
+	a1p := (1 + g) * c1.A
+	a2p := (1 + g) * c2.A
+	c1p := math.Hypot(a1p, c1.B)
+	c2p := math.Hypot(a2p, c2.B)
+
+	hueAngle := func(b, ap float64) float64 {
+		if b == 0 && ap == 0 {
+			return 0
+		}
+
+		h := deg(math.Atan2(b, ap))
+		if h < 0 {
+			h += 360
+		}
+
+		return h
+	}
+
+	h1p := hueAngle(c1.B, a1p)
+	h2p := hueAngle(c2.B, a2p)
+
+	dLp := c2.L - c1.L
+	dCp := c2p - c1p
+
+	var dhp float64
+
+	if c1p*c2p != 0 {
+		dhp = h2p - h1p
+
+		switch {
+		case dhp > 180:
+			dhp -= 360
+		case dhp < -180:
+			dhp += 360
+		}
+	}
+
+	dHp := 2 * math.Sqrt(c1p*c2p) * math.Sin(rad(dhp/2))
+
+	lBarp := (c1.L + c2.L) / 2
+	cBarp := (c1p + c2p) / 2
+
+	hBarp := h1p + h2p
+
+	if c1p*c2p != 0 {
+		switch {
+		case math.Abs(h1p-h2p) <= 180:
+			hBarp /= 2
+		case h1p+h2p < 360:
+			hBarp = (hBarp + 360) / 2
+		default:
+			hBarp = (hBarp - 360) / 2
+		}
+	}
+
+	t := 1 -
+		0.17*math.Cos(rad(hBarp-30)) +
+		0.24*math.Cos(rad(2*hBarp)) +
+		0.32*math.Cos(rad(3*hBarp+6)) -
+		0.20*math.Cos(rad(4*hBarp-63))
+
+	dTheta := 30 * math.Exp(-math.Pow((hBarp-275)/25, 2))
+	cBarp7 := math.Pow(cBarp, 7)
+	rC := 2 * math.Sqrt(cBarp7/(cBarp7+pow25To7))
+
+	lBarp50 := (lBarp - 50) * (lBarp - 50)
+	sL := 1 + (0.015*lBarp50)/math.Sqrt(20+lBarp50)
+	sC := 1 + 0.045*cBarp
+	sH := 1 + 0.015*cBarp*t
+	rT := -math.Sin(rad(2*dTheta)) * rC
+
+	lTerm := dLp / sL
+	cTerm := dCp / sC
+	hTerm := dHp / sH
+
+	return math.Sqrt(lTerm*lTerm + cTerm*cTerm + hTerm*hTerm +
+		rT*cTerm*hTerm)
+}
@@ -0,0 +1,162 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"math"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestDeltaE(t *testing.T) {
+	const epsilon = 0.0001
+
+	testCases := []struct {
+		testhelper.ID
+		f      DeltaEFormula
+		c1, c2 Lab
+		expVal float64
+	}{
+		// the CIEDE2000 test values are from Sharma, Wu and Dalal (2005)
+		{
+			ID:     testhelper.MkID("CIEDE2000 - Sharma 1"),
+			f:      CIEDE2000,
+			c1:     Lab{L: 50, A: 2.6772, B: -79.7751},
+			c2:     Lab{L: 50, A: 0, B: -82.7485},
+			expVal: 2.0425,
+		},
+		{
+			ID:     testhelper.MkID("CIEDE2000 - Sharma 7"),
+			f:      CIEDE2000,
+			c1:     Lab{L: 50, A: 0, B: 0},
+			c2:     Lab{L: 50, A: -1, B: 2},
+			expVal: 2.3669,
+		},
+		{
+			ID:     testhelper.MkID("CIEDE2000 - Sharma 13"),
+			f:      CIEDE2000,
+			c1:     Lab{L: 50, A: 2.49, B: -0.001},
+			c2:     Lab{L: 50, A: -2.49, B: 0.0009},
+			expVal: 7.1792,
+		},
+		{
+			ID:     testhelper.MkID("CIEDE2000 - Sharma 17"),
+			f:      CIEDE2000,
+			c1:     Lab{L: 50, A: 2.5, B: 0},
+			c2:     Lab{L: 73, A: 25, B: -18},
+			expVal: 27.1492,
+		},
+		{
+			ID:     testhelper.MkID("CIEDE2000 - Sharma 25"),
+			f:      CIEDE2000,
+			c1:     Lab{L: 60.2574, A: -34.0099, B: 36.2677},
+			c2:     Lab{L: 60.4626, A: -34.1751, B: 39.4387},
+			expVal: 1.2644,
+		},
+		{
+			ID:     testhelper.MkID("CIEDE2000 - Sharma 34"),
+			f:      CIEDE2000,
+			c1:     Lab{L: 2.0776, A: 0.0795, B: -1.135},
+			c2:     Lab{L: 0.9033, A: -0.0636, B: -0.5514},
+			expVal: 0.9082,
+		},
+		{
+			ID:     testhelper.MkID("CIE76"),
+			f:      CIE76,
+			c1:     Lab{L: 50, A: 0, B: 0},
+			c2:     Lab{L: 53, A: 4, B: 0},
+			expVal: 5,
+		},
+		{
+			ID:     testhelper.MkID("CIE94 - lightness only"),
+			f:      CIE94,
+			c1:     Lab{L: 50, A: 20, B: 0},
+			c2:     Lab{L: 53, A: 20, B: 0},
+			expVal: 3,
+		},
+		{
+			ID:     testhelper.MkID("CIE94 - chroma only"),
+			f:      CIE94,
+			c1:     Lab{L: 50, A: 20, B: 0},
+			c2:     Lab{L: 50, A: 29.5, B: 0},
+			expVal: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		dist := tc.f.labDist(tc.c1, tc.c2)
+		if math.Abs(dist-tc.expVal) > epsilon {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected: %.4f", tc.expVal)
+			t.Logf("\t:      got: %.4f", dist)
+			t.Error("\t: unexpected Delta E (" + tc.f.String() + ")")
+		}
+	}
+}
+
+// TestDeltaEFuncs checks the colour difference functions against published
+// values for pairs of sRGB colours (D65 white point). The difference
+// between black and white is exactly 100 by each of the formulae. The small
+// tolerance allows for differences in the precision of the sRGB to L*a*b*
+// conversion used by other implementations.
+func TestDeltaEFuncs(t *testing.T) {
+	const epsilon = 0.01
+
+	red := color.RGBA{R: 0xff, A: 0xff}                     //nolint:misspell
+	transRed := color.RGBA{R: 0xff, A: 0x10}                //nolint:misspell
+	blue := color.RGBA{B: 0xff, A: 0xff}                    //nolint:misspell
+	black := color.RGBA{A: 0xff}                            //nolint:misspell
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff} //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		f      func(c1, c2 color.RGBA) float64 //nolint:misspell
+		c1, c2 color.RGBA                      //nolint:misspell
+		expVal float64
+	}{
+		{
+			ID: testhelper.MkID("CIE76 - red/blue"),
+			f:  DeltaE76, c1: red, c2: blue, expVal: 176.31,
+		},
+		{
+			ID: testhelper.MkID("CIE94 - red/blue"),
+			f:  DeltaE94, c1: red, c2: blue, expVal: 70.58,
+		},
+		{
+			ID: testhelper.MkID("CIEDE2000 - red/blue"),
+			f:  DeltaE2000, c1: red, c2: blue, expVal: 52.88,
+		},
+		{
+			ID: testhelper.MkID("CIE76 - black/white"),
+			f:  DeltaE76, c1: black, c2: white, expVal: 100,
+		},
+		{
+			ID: testhelper.MkID("CIE94 - black/white"),
+			f:  DeltaE94, c1: black, c2: white, expVal: 100,
+		},
+		{
+			ID: testhelper.MkID("CIEDE2000 - black/white"),
+			f:  DeltaE2000, c1: black, c2: white, expVal: 100,
+		},
+	}
+
+	for _, tc := range testCases {
+		dist := tc.f(tc.c1, tc.c2)
+		if math.Abs(dist-tc.expVal) > epsilon {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected: %.2f", tc.expVal)
+			t.Logf("\t:      got: %.2f", dist)
+			t.Error("\t: unexpected Delta E")
+		}
+
+		if tc.f(tc.c1, tc.c1) != 0 {
+			t.Log(tc.IDStr())
+			t.Error("\t: a colour should not differ from itself")
+		}
+
+		if tc.f(red, transRed) != 0 {
+			t.Log(tc.IDStr())
+			t.Error("\t: the alpha channel should be ignored")
+		}
+	}
+}
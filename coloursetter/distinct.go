@@ -0,0 +1,346 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// These are the limits and default values for the distinct colour
+// generator
+const (
+	// MaxDistinctColours is the largest number of colours that
+	// DistinctColours will generate
+	MaxDistinctColours = 256
+
+	DfltDistinctMinL      = 0.4
+	DfltDistinctMaxL      = 0.85
+	DfltDistinctMinChroma = 0.08
+	DfltDistinctMaxChroma = 0.3
+
+	distinctCandidates = 2000
+	distinctMaxTries   = 200 * distinctCandidates
+	okLabMaxAB         = 0.4
+)
+
+// DistinctOptions holds the parameters controlling the generation of
+// distinct colours. If both the lightness bounds are zero the default
+// bounds are used and similarly for the chroma bounds.
+type DistinctOptions struct {
+	// MinL and MaxL bound the OKLab lightness (0.0 to 1.0) of the
+	// generated colours
+	MinL, MaxL float64
+	// MinChroma and MaxChroma bound the OKLab chroma (0.0 to about 0.37)
+	// of the generated colours
+	MinChroma, MaxChroma float64
+	// Seed is used to generate the candidate colours. The same seed (and
+	// the same other options) always gives the same colours.
+	Seed uint64
+	// Avoid, if not nil, is a colour (typically the background) from
+	// which the generated colours should be as distinct as possible
+	Avoid *color.RGBA //nolint:misspell
+	// CVDSafe, if set, causes the colours to be chosen so that they are
+	// also distinct when seen by someone with protanopia, deuteranopia or
+	// tritanopia
+	CVDSafe bool
+}
+
+// withDefaults returns the options with the default bounds applied
+func (o DistinctOptions) withDefaults() DistinctOptions {
+	if o.MinL == 0 && o.MaxL == 0 {
+		o.MinL, o.MaxL = DfltDistinctMinL, DfltDistinctMaxL
+	}
+
+	if o.MinChroma == 0 && o.MaxChroma == 0 {
+		o.MinChroma, o.MaxChroma = DfltDistinctMinChroma, DfltDistinctMaxChroma
+	}
+
+	return o
+}
+
+// Check returns a non-nil error if the options are invalid
+func (o DistinctOptions) Check() error {
+	o = o.withDefaults()
+
+	if o.MinL < 0 || o.MaxL > 1 || o.MinL > o.MaxL {
+		return fmt.Errorf("bad lightness bounds: %g to %g"+
+			" (they must be from 0.0 to 1.0 and the first"+
+			" must not exceed the second)", o.MinL, o.MaxL)
+	}
+
+	if o.MinChroma < 0 || o.MinChroma > o.MaxChroma {
+		return fmt.Errorf("bad chroma bounds: %g to %g"+
+			" (they must not be negative and the first"+
+			" must not exceed the second)", o.MinChroma, o.MaxChroma)
+	}
+
+	return nil
+}
+
+// distinctCandidate is a potential distinct colour together with its OKLab
+// values as seen with normal colour vision and (optionally) with each of
+// the colour vision deficiencies
+type distinctCandidate struct {
+	c     color.RGBA //nolint:misspell
+	views []OKLab
+}
+
+// makeDistinctCandidate returns the candidate for the colour
+func makeDistinctCandidate(c color.RGBA, cvdSafe bool) distinctCandidate { //nolint:misspell
+	dc := distinctCandidate{c: c, views: []OKLab{MakeOKLab(c)}}
+
+	if cvdSafe {
+		for _, t := range AllCVDTypes {
+			dc.views = append(dc.views, MakeOKLab(SimulateCVD(c, t)))
+		}
+	}
+
+	return dc
+}
+
+// dist returns the smallest distance between the two candidates over all
+// of their views
+func (dc distinctCandidate) dist(other distinctCandidate) float64 {
+	d := math.MaxFloat64
+	for i, v := range dc.views {
+		d = min(d, v.Dist(other.views[i]))
+	}
+
+	return d
+}
+
+// distinctCandidateColours returns candidate colours within the bounds
+// given by the options, generated from the seed
+func distinctCandidateColours(o DistinctOptions) []distinctCandidate {
+	r := rand.New(rand.NewPCG(o.Seed, o.Seed^0x9e3779b97f4a7c15)) //nolint:gosec,mnd
+
+	candidates := make([]distinctCandidate, 0, distinctCandidates)
+	seen := map[color.RGBA]bool{} //nolint:misspell
+
+	for range distinctMaxTries {
+		if len(candidates) == distinctCandidates {
+			break
+		}
+
+		ok := OKLab{
+			L: o.MinL + r.Float64()*(o.MaxL-o.MinL),
+			A: (2*r.Float64() - 1) * okLabMaxAB,
+			B: (2*r.Float64() - 1) * okLabMaxAB,
+		}
+
+		if chroma := ok.Chroma(); chroma < o.MinChroma || chroma > o.MaxChroma {
+			continue
+		}
+
+		if !ok.InGamut() {
+			continue
+		}
+
+		c := ok.ToRGBA()
+		if seen[c] {
+			continue
+		}
+
+		seen[c] = true
+
+		candidates = append(candidates, makeDistinctCandidate(c, o.CVDSafe))
+	}
+
+	return candidates
+}
+
+// DistinctColours returns n colours chosen to be as distinguishable from
+// each other as possible. The colours are spread through the OKLab colour
+// space within the lightness and chroma bounds given in the options. Each
+// colour is chosen in turn to be as far as possible from those already
+// chosen (and from the colour to be avoided) so the earlier colours are the
+// most distinct; the result is always the same for the same options.
+func DistinctColours(n int, o DistinctOptions) ([]color.RGBA, error) { //nolint:misspell
+	if n < 1 || n > MaxDistinctColours {
+		return nil, fmt.Errorf("bad number of distinct colours: %d"+
+			" (it must be from 1 to %d)", n, MaxDistinctColours)
+	}
+
+	if err := o.Check(); err != nil {
+		return nil, err
+	}
+
+	o = o.withDefaults()
+
+	candidates := distinctCandidateColours(o)
+	if len(candidates) < n {
+		return nil, errors.New("cannot generate enough distinct colours:" +
+			" the lightness and chroma bounds are too narrow")
+	}
+
+	// minDist holds, for each candidate, the distance to the nearest
+	// colour already chosen (or to be avoided)
+	minDist := make([]float64, len(candidates))
+
+	for i := range minDist {
+		minDist[i] = math.MaxFloat64
+	}
+
+	update := func(chosen distinctCandidate) {
+		for i, cand := range candidates {
+			minDist[i] = min(minDist[i], cand.dist(chosen))
+		}
+	}
+
+	if o.Avoid != nil {
+		update(makeDistinctCandidate(*o.Avoid, o.CVDSafe))
+	} else {
+		// start from the candidate most distant from a mid grey
+		grey := color.RGBA{R: 0x77, G: 0x77, B: 0x77, A: math.MaxUint8} //nolint:misspell
+		update(makeDistinctCandidate(grey, o.CVDSafe))
+	}
+
+	colours := make([]color.RGBA, 0, n) //nolint:misspell
+
+	for range n {
+		best := -1
+		for i, d := range minDist {
+			if d >= 0 && (best < 0 || d > minDist[best]) {
+				best = i
+			}
+		}
+
+		colours = append(colours, candidates[best].c)
+		update(candidates[best])
+		minDist[best] = -1 // mark it as chosen
+	}
+
+	return colours, nil
+}
+
+// These introduce the distinct colour notation in a colour list
+const (
+	distinctPrefix     = "distinct:"
+	distinctFuncPrefix = "distinct("
+)
+
+// parseBounds parses a pair of values separated by a colon
+func parseBounds(name, s string) (float64, float64, error) {
+	loStr, hiStr, found := strings.Cut(s, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("bad %s bounds %q: expected lo:hi", name, s)
+	}
+
+	lo, errLo := strconv.ParseFloat(strings.TrimSpace(loStr), 64)
+	hi, errHi := strconv.ParseFloat(strings.TrimSpace(hiStr), 64)
+
+	if errLo != nil || errHi != nil {
+		return 0, 0, fmt.Errorf("bad %s bounds %q: expected lo:hi", name, s)
+	}
+
+	return lo, hi, nil
+}
+
+// parseDistinct parses a distinct colour entry, either "distinct:N" or
+// "distinct(N, option, ...)", and returns the generated colours. The
+// options are:
+//
+//	l=lo:hi    the lightness bounds
+//	c=lo:hi    the chroma bounds
+//	seed=N     the seed for the candidate colours
+//	avoid=col  a colour (typically the background) to avoid
+//	cvd        make the colours distinct for colour-blind people
+func (p colourParser) parseDistinct(s string) ([]color.RGBA, error) { //nolint:misspell
+	lc := strings.ToLower(strings.TrimSpace(s))
+
+	var parts []string
+
+	switch {
+	case strings.HasPrefix(lc, distinctPrefix):
+		parts = []string{strings.TrimSpace(s)[len(distinctPrefix):]}
+	case strings.HasPrefix(lc, distinctFuncPrefix) && strings.HasSuffix(lc, ")"):
+		args := strings.TrimSpace(s)
+		args = args[len(distinctFuncPrefix) : len(args)-1]
+		parts = strings.Split(args, ",")
+	default:
+		return nil, fmt.Errorf("bad distinct colours %q:"+
+			" expected %sN or %sN, option, ...)",
+			s, distinctPrefix, distinctFuncPrefix)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("bad distinct colours %q:"+
+			" the number of colours (%q) is not a number", s, parts[0])
+	}
+
+	var o DistinctOptions
+
+	for _, opt := range parts[1:] {
+		if err := p.parseDistinctOpt(&o, strings.TrimSpace(opt)); err != nil {
+			return nil, fmt.Errorf("bad distinct colours %q: %w", s, err)
+		}
+	}
+
+	colours, err := DistinctColours(n, o)
+	if err != nil {
+		return nil, fmt.Errorf("bad distinct colours %q: %w", s, err)
+	}
+
+	return colours, nil
+}
+
+// parseDistinctOpt parses a single distinct colour option and records it in
+// the DistinctOptions
+func (p colourParser) parseDistinctOpt(o *DistinctOptions, opt string) error {
+	name, val, hasVal := strings.Cut(opt, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if name == "cvd" && !hasVal {
+		o.CVDSafe = true
+		return nil
+	}
+
+	if !hasVal {
+		return fmt.Errorf("unknown option: %q", opt)
+	}
+
+	var err error
+
+	switch name {
+	case "l":
+		o.MinL, o.MaxL, err = parseBounds("lightness", val)
+	case "c":
+		o.MinChroma, o.MaxChroma, err = parseBounds("chroma", val)
+	case "seed":
+		o.Seed, err = strconv.ParseUint(strings.TrimSpace(val), 0, 64)
+		if err != nil {
+			err = fmt.Errorf("bad seed %q: %w", val, err)
+		}
+	case "avoid":
+		var c color.RGBA //nolint:misspell
+
+		c, err = p.parseRGBA(val)
+		o.Avoid = &c
+	default:
+		err = fmt.Errorf("unknown option: %q", opt)
+	}
+
+	return err
+}
+
+// distinctAllowedValues describes the distinct colour notation
+func distinctAllowedValues() string {
+	return distinctPrefix + "N to generate N (up to " +
+		strconv.Itoa(MaxDistinctColours) + ")" +
+		" colours chosen to be as distinguishable as possible," +
+		" or " + distinctFuncPrefix + "N, option, ...)" +
+		" where the options are l=lo:hi (the lightness bounds, default " +
+		strconv.FormatFloat(DfltDistinctMinL, 'g', -1, 64) + ":" +
+		strconv.FormatFloat(DfltDistinctMaxL, 'g', -1, 64) + ")," +
+		" c=lo:hi (the chroma bounds, default " +
+		strconv.FormatFloat(DfltDistinctMinChroma, 'g', -1, 64) + ":" +
+		strconv.FormatFloat(DfltDistinctMaxChroma, 'g', -1, 64) + ")," +
+		" seed=N (a different seed gives different colours)," +
+		" avoid=colour (a colour, typically the background, to avoid)" +
+		" and cvd (keep the colours distinct for colour-blind people)"
+}
@@ -0,0 +1,204 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestOKLab(t *testing.T) {
+	const epsilon = 0.001
+
+	testCases := []struct {
+		testhelper.ID
+		c      color.RGBA //nolint:misspell
+		expVal OKLab
+	}{
+		{
+			ID:     testhelper.MkID("white"),
+			c:      color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, //nolint:misspell
+			expVal: OKLab{L: 1},
+		},
+		{
+			ID:     testhelper.MkID("black"),
+			c:      color.RGBA{A: 0xff}, //nolint:misspell
+			expVal: OKLab{},
+		},
+		{
+			ID:     testhelper.MkID("red"),
+			c:      color.RGBA{R: 0xff, A: 0xff}, //nolint:misspell
+			expVal: OKLab{L: 0.62796, A: 0.22486, B: 0.12585},
+		},
+	}
+
+	for _, tc := range testCases {
+		ok := MakeOKLab(tc.c)
+		if math.Abs(ok.L-tc.expVal.L) > epsilon ||
+			math.Abs(ok.A-tc.expVal.A) > epsilon ||
+			math.Abs(ok.B-tc.expVal.B) > epsilon {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected: %v", tc.expVal)
+			t.Logf("\t:      got: %v", ok)
+			t.Error("\t: unexpected OKLab value")
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "round trip",
+			ChannelOrderRGBA.Hex(ok.ToRGBA()), ChannelOrderRGBA.Hex(tc.c))
+	}
+
+	if (OKLab{L: 0.5, A: 0.4, B: 0.4}).InGamut() {
+		t.Error("an extreme OKLab colour should not be in the sRGB gamut")
+	}
+}
+
+func TestDistinctColours(t *testing.T) {
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff} //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		n int
+		o DistinctOptions
+	}{
+		{
+			ID: testhelper.MkID("defaults"),
+			n:  8,
+		},
+		{
+			ID: testhelper.MkID("all options"),
+			n:  5,
+			o: DistinctOptions{
+				MinL: 0.5, MaxL: 0.7,
+				MinChroma: 0.1, MaxChroma: 0.2,
+				Seed:    42,
+				Avoid:   &white,
+				CVDSafe: true,
+			},
+		},
+		{
+			ID: testhelper.MkID("too few"),
+			ExpErr: testhelper.MkExpErr(
+				"bad number of distinct colours: 0 (it must be from 1 to 256)"),
+			n: 0,
+		},
+		{
+			ID:     testhelper.MkID("bad lightness"),
+			ExpErr: testhelper.MkExpErr("bad lightness bounds: 0.8 to 0.2"),
+			n:      3,
+			o:      DistinctOptions{MinL: 0.8, MaxL: 0.2},
+		},
+		{
+			ID:     testhelper.MkID("bad chroma"),
+			ExpErr: testhelper.MkExpErr("bad chroma bounds: -1 to 0.2"),
+			n:      3,
+			o:      DistinctOptions{MinChroma: -1, MaxChroma: 0.2},
+		},
+		{
+			ID: testhelper.MkID("impossible bounds"),
+			ExpErr: testhelper.MkExpErr("cannot generate enough distinct colours:" +
+				" the lightness and chroma bounds are too narrow"),
+			n: 3,
+			o: DistinctOptions{MinL: 0.01, MaxL: 0.02, MinChroma: 0.3, MaxChroma: 0.3},
+		},
+	}
+
+	for _, tc := range testCases {
+		colours, err := DistinctColours(tc.n, tc.o)
+		if !testhelper.CheckExpErr(t, err, tc) || err != nil {
+			continue
+		}
+
+		if len(colours) != tc.n {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: %d colours expected, %d generated", tc.n, len(colours))
+		}
+
+		again, _ := DistinctColours(tc.n, tc.o)
+		if !slices.Equal(colours, again) {
+			t.Log(tc.IDStr())
+			t.Error("\t: the colours should be the same each time")
+		}
+
+		o := tc.o.withDefaults()
+		for _, c := range colours {
+			ok := MakeOKLab(c)
+			if ok.L < o.MinL-0.01 || ok.L > o.MaxL+0.01 {
+				t.Log(tc.IDStr())
+				t.Errorf("\t: the lightness of %s (%g) is out of bounds",
+					ChannelOrderRGB.Hex(c), ok.L)
+			}
+		}
+	}
+}
+
+func TestDistinctSeed(t *testing.T) {
+	c1, err1 := DistinctColours(4, DistinctOptions{Seed: 1})
+	c2, err2 := DistinctColours(4, DistinctOptions{Seed: 2})
+
+	if err1 != nil || err2 != nil {
+		t.Fatal("unexpected errors: ", err1, err2)
+	}
+
+	if slices.Equal(c1, c2) {
+		t.Error("different seeds should give different colours")
+	}
+}
+
+func TestPaletteDistinct(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v      string
+		expLen int
+	}{
+		{
+			ID:     testhelper.MkID("distinct:N"),
+			v:      "distinct:12",
+			expLen: 12,
+		},
+		{
+			ID:     testhelper.MkID("distinct(...) with other colours"),
+			v:      "black,distinct(3, l=0.5:0.8, c=0.1:0.2, seed=7, avoid=black, cvd)",
+			expLen: 4,
+		},
+		{
+			ID: testhelper.MkID("bad count"),
+			ExpErr: testhelper.MkExpErr(`bad palette entry 1 ("distinct:x"):`,
+				`bad distinct colours "distinct:x":`+
+					` the number of colours ("x") is not a number`),
+			v: "distinct:x",
+		},
+		{
+			ID: testhelper.MkID("bad option"),
+			ExpErr: testhelper.MkExpErr(
+				`bad distinct colours "distinct(3, x=1)": unknown option: "x=1"`),
+			v: "distinct(3, x=1)",
+		},
+		{
+			ID: testhelper.MkID("bad bounds"),
+			ExpErr: testhelper.MkExpErr(
+				`bad distinct colours "distinct(3, l=0.5)":` +
+					` bad lightness bounds "0.5": expected lo:hi`),
+			v: "distinct(3, l=0.5)",
+		},
+		{
+			ID:     testhelper.MkID("bad avoid colour"),
+			ExpErr: testhelper.MkExpErr(`"nonesuch"`),
+			v:      "distinct(3, avoid=nonesuch)",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v color.Palette //nolint:misspell
+
+		err := Palette{Value: &v}.SetWithVal("", tc.v)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil &&
+			len(v) != tc.expLen {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: %d colours expected, %d found", tc.expLen, len(v))
+		}
+	}
+}
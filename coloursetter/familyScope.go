@@ -0,0 +1,120 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+)
+
+// scopedSetting records a parameter value whose colour names are to be
+// resolved once the colour families are known
+type scopedSetting struct {
+	paramName string
+	set       func() error
+}
+
+// FamilyScope allows the colour families used to interpret colour names to
+// be given by a parameter. The Value is shared between a Families setter
+// and the RGB, RGBPair and NamedColour setters having this FamilyScope as
+// their Scope. The values given to those setters are recorded and the
+// colour names are resolved, using the final setting of the families, once
+// all the parameters have been parsed. This means that the result does not
+// depend on the order in which the parameters are given.
+//
+// A typical use would be:
+//
+//	fl := colour.Families{}
+//	scope := coloursetter.NewFamilyScope(&fl)
+//
+//	ps.Add("colour-families", coloursetter.Families{Value: &fl}, ...)
+//	ps.Add("fg", coloursetter.RGB{Value: &fg, Scope: scope}, ...)
+//	scope.AddFinalCheck(ps)
+//
+// The scope's final check must be added before any other final checks
+// which use the colours. If the Value is empty the setters use their
+// default families.
+type FamilyScope struct {
+	Value *colour.Families
+
+	pending []scopedSetting
+}
+
+// NewFamilyScope returns a new FamilyScope sharing the families
+func NewFamilyScope(fl *colour.Families) *FamilyScope {
+	return &FamilyScope{Value: fl}
+}
+
+// families returns the families from the scope or, if the scope is nil,
+// the default families
+func (fs *FamilyScope) families(dflt colour.Families) colour.Families {
+	if fs == nil {
+		return dflt
+	}
+
+	return *fs.Value
+}
+
+// record saves the setting so that it can be resolved once all the
+// parameters have been parsed. The setting is also applied immediately,
+// using the families as currently set, so that the value is set if the
+// families are not changed; any error is ignored until the setting is
+// resolved.
+func (fs *FamilyScope) record(paramName string, set func() error) {
+	fs.pending = append(fs.pending,
+		scopedSetting{paramName: paramName, set: set})
+
+	_ = set()
+}
+
+// resolve applies all the recorded settings, in the order in which they
+// were given, using the final setting of the families. The recorded
+// settings are then discarded. It calls the report function for each
+// setting that fails.
+func (fs *FamilyScope) resolve(report func(paramName string, err error)) {
+	for _, s := range fs.pending {
+		if err := s.set(); err != nil {
+			report(s.paramName, err)
+		}
+	}
+
+	fs.pending = nil
+}
+
+// Resolve sets the values of the parameters in the scope using the final
+// setting of the families. It returns an error reporting every value that
+// cannot be resolved, each attributed to its parameter, or nil if there
+// are none. It need not be called if AddFinalCheck has been used.
+func (fs *FamilyScope) Resolve() error {
+	errs := []error{}
+
+	fs.resolve(func(paramName string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %w", paramName, err))
+	})
+
+	return errors.Join(errs...)
+}
+
+// AddFinalCheck adds a final check to the param set which resolves the
+// values of the parameters in the scope once all the parameters have been
+// parsed. Any errors are reported against the parameter that was given
+// the bad value.
+func (fs *FamilyScope) AddFinalCheck(ps *param.PSet) {
+	ps.AddFinalCheck(func() error {
+		fs.resolve(func(paramName string, err error) {
+			ps.AddErr(paramName, err)
+		})
+
+		return nil
+	})
+}
+
+// check returns a non-nil error if the scope is not nil but its Value is
+func (fs *FamilyScope) check() error {
+	if fs != nil && fs.Value == nil {
+		return errors.New("the Value is nil")
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package coloursetter
+
+import (
+	"errors"
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestFamilyScope(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		args    []string
+		expFG   string
+		expPair string
+		expName string
+	}{
+		{
+			ID:      testhelper.MkID("default families"),
+			args:    []string{"-fg", "gray", "-pair", "green;gray", "-name", "gray"},
+			expFG:   "#808080ff",
+			expPair: "#008000ff;#808080ff",
+			expName: "gray #808080ff",
+		},
+		{
+			ID: testhelper.MkID("families given last"),
+			args: []string{
+				"-fg", "gray", "-pair", "green;gray", "-name", "gray",
+				"-families", "x11",
+			},
+			expFG:   "#bebebeff",
+			expPair: "#00ff00ff;#bebebeff",
+			expName: "gray #bebebeff",
+		},
+		{
+			ID: testhelper.MkID("families given first"),
+			args: []string{
+				"-families", "x11",
+				"-fg", "gray", "-pair", "green;gray", "-name", "gray",
+			},
+			expFG:   "#bebebeff",
+			expPair: "#00ff00ff;#bebebeff",
+			expName: "gray #bebebeff",
+		},
+		{
+			ID: testhelper.MkID("name only valid in the later families"),
+			args: []string{
+				"-fg", "lightgoldenrod", "-families", "x11",
+			},
+			expFG:   "#eedd82ff",
+			expPair: "#000000ff;#ffffffff",
+			expName: "black #000000ff",
+		},
+		{
+			ID: testhelper.MkID("name not valid in the final families"),
+			ExpErr: testhelper.MkExpErr(
+				`fg: bad colour name: "lightgoldenrod"`,
+				`pair: bad colour name: "lightgoldenrod"`),
+			args: []string{
+				"-families", "x11",
+				"-fg", "lightgoldenrod", "-pair", "lightgoldenrod;auto",
+				"-families", "web",
+			},
+			expFG:   "#eedd82ff",
+			expPair: "#eedd82ff;#000000ff",
+			expName: "black #000000ff",
+		},
+	}
+
+	for _, tc := range testCases {
+		var (
+			fl  colour.Families
+			fg  = color.RGBA{A: 0xff}                                  //nolint:misspell
+			p1  = color.RGBA{A: 0xff}                                  //nolint:misspell
+			p2  = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}       //nolint:misspell
+			nc  = colour.MakeNamedColour("black", color.RGBA{A: 0xff}) //nolint:misspell
+			ps  = param.NewSet(quietHelper{})
+			scp = NewFamilyScope(&fl)
+		)
+
+		ps.Add("families", Families{Value: &fl}, "the colour families")
+		ps.Add("fg", RGB{Value: &fg, Scope: scp, ShowHex: true},
+			"the text colour")
+		ps.Add("pair",
+			&RGBPair{Value1: &p1, Value2: &p2, Scope: scp, ShowHex: true},
+			"a pair of colours")
+		ps.Add("name", NamedColour{Value: &nc, Scope: scp, ShowHex: true},
+			"a named colour")
+		scp.AddFinalCheck(ps)
+
+		ps.Parse(tc.args)
+
+		errs := []error{}
+		for _, name := range []string{"fg", "pair", "name"} {
+			for _, err := range ps.Errors()[name] {
+				errs = append(errs, errors.New(name+": "+err.Error()))
+			}
+		}
+
+		testhelper.CheckExpErr(t, errors.Join(errs...), tc)
+		testhelper.DiffString(t, tc.IDStr(), "fg",
+			ChannelOrderRGBA.Hex(fg), tc.expFG)
+		testhelper.DiffString(t, tc.IDStr(), "pair",
+			ChannelOrderRGBA.Hex(p1)+";"+ChannelOrderRGBA.Hex(p2), tc.expPair)
+		testhelper.DiffString(t, tc.IDStr(), "name",
+			nc.Name()+" "+ChannelOrderRGBA.Hex(nc.Colour()), tc.expName)
+	}
+}
+
+func TestFamilyScopeResolve(t *testing.T) {
+	web, err := colour.GetFamily("web")
+	if err != nil {
+		t.Fatal("cannot get the web colour family: ", err)
+	}
+
+	fl := colour.Families{web}
+	scp := NewFamilyScope(&fl)
+
+	var fg color.RGBA //nolint:misspell
+
+	s := RGB{Value: &fg, Scope: scp}
+
+	if err := s.SetWithVal("fg", "lightgoldenrod"); err != nil {
+		t.Fatal("the error should be deferred, got: ", err)
+	}
+
+	tc := struct {
+		testhelper.ID
+		testhelper.ExpErr
+	}{
+		ID:     testhelper.MkID("unresolved name"),
+		ExpErr: testhelper.MkExpErr(`fg: bad colour name: "lightgoldenrod"`),
+	}
+
+	testhelper.CheckExpErr(t, scp.Resolve(), tc)
+
+	if err := scp.Resolve(); err != nil {
+		t.Error("the settings should be discarded once resolved, got: ", err)
+	}
+
+	panicked, panicVal := testhelper.PanicSafe(func() {
+		RGB{Value: &fg, Scope: &FamilyScope{}}.CheckSetter("fg")
+	})
+	testhelper.CheckExpPanic(t, panicked, panicVal,
+		struct {
+			testhelper.ID
+			testhelper.ExpPanic
+		}{
+			ID: testhelper.MkID("nil scope value"),
+			ExpPanic: testhelper.MkExpPanic(
+				"fg: coloursetter.RGB Check failed: RGB.Scope: the Value is nil"),
+		})
+}
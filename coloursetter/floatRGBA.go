@@ -0,0 +1,242 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// FloatColour holds a colour as floating point channel values. The red,
+// green and blue channels are nominally in the range 0.0 to 1.0 but may be
+// greater than 1.0 for high dynamic range (HDR) colours. The alpha channel
+// is in the range 0.0 (fully transparent) to 1.0 (fully opaque).
+type FloatColour struct {
+	R, G, B, A float32
+}
+
+// String returns the colour in the form accepted by the FloatRGBA setter
+func (fc FloatColour) String() string {
+	return fmt.Sprintf("rgbf(%g, %g, %g, %g)", fc.R, fc.G, fc.B, fc.A)
+}
+
+var rgbfRE = regexp.MustCompile(
+	`^[[:space:]]*[rR][gG][bB][fF][[:space:]]*\((.*)\)[[:space:]]*$`)
+
+// srgbToLinear decodes an sRGB-encoded channel value in the range 0.0 to
+// 1.0 into a linear value
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 { //nolint:mnd
+		return v / 12.92 //nolint:mnd
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4) //nolint:mnd
+}
+
+// linearToSRGB encodes a linear channel value in the range 0.0 to 1.0 into
+// an sRGB value
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 { //nolint:mnd
+		return v * 12.92 //nolint:mnd
+	}
+
+	return 1.055*math.Pow(v, 1/2.4) - 0.055 //nolint:mnd
+}
+
+// MakeFloatColour converts the colour into a FloatColour. If linear is true
+// the red, green and blue channels are decoded from sRGB into linear values,
+// otherwise they are simply scaled into the range 0.0 to 1.0. The alpha
+// channel is always simply scaled.
+func MakeFloatColour(c color.RGBA, linear bool) FloatColour { //nolint:misspell
+	conv := func(v uint8) float32 {
+		f := float64(v) / math.MaxUint8
+		if linear {
+			f = srgbToLinear(f)
+		}
+
+		return float32(f)
+	}
+
+	return FloatColour{
+		R: conv(c.R),
+		G: conv(c.G),
+		B: conv(c.B),
+		A: float32(float64(c.A) / math.MaxUint8),
+	}
+}
+
+// ToRGBA converts the FloatColour into a color.RGBA, the inverse of
+// MakeFloatColour. If linear is true the red, green and blue channels are
+// encoded from linear values into sRGB, otherwise they are simply scaled.
+// The alpha channel is always simply scaled. Channel values outside the range 0.0 to 1.0 (such as HDR values) are
+// clamped.
+func (fc FloatColour) ToRGBA(linear bool) color.RGBA { //nolint:misspell
+	conv := func(v float32) uint8 {
+		f := max(0, min(1, float64(v)))
+		if linear {
+			f = linearToSRGB(f)
+		}
+
+		return clampUint8(f * math.MaxUint8)
+	}
+
+	return color.RGBA{ //nolint:misspell
+		R: conv(fc.R),
+		G: conv(fc.G),
+		B: conv(fc.B),
+		A: clampUint8(max(0, min(1, float64(fc.A))) * math.MaxUint8),
+	}
+}
+
+// parseRGBF parses the parts of an rgbf(...) colour. There must be three
+// or four comma-separated values, the red, green and blue values must not
+// be negative and the alpha value, if given, must be in the range 0.0 to
+// 1.0.
+func parseRGBF(s, parts string) (FloatColour, error) {
+	vals := strings.Split(parts, ",")
+	if len(vals) != 3 && len(vals) != 4 { //nolint:mnd
+		return FloatColour{},
+			fmt.Errorf("bad rgbf colour %q: 3 or 4 values expected, %d found",
+				s, len(vals))
+	}
+
+	names := []string{"red", "green", "blue", "alpha"}
+	fv := []float32{0, 0, 0, 1}
+
+	for i, v := range vals {
+		v = strings.TrimSpace(v)
+
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return FloatColour{},
+				fmt.Errorf("bad rgbf colour %q: the %s value (%q)"+
+					" is not a number",
+					s, names[i], v)
+		}
+
+		if f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+			return FloatColour{},
+				fmt.Errorf("bad rgbf colour %q: the %s value (%q)"+
+					" must be a finite, non-negative number",
+					s, names[i], v)
+		}
+
+		if names[i] == "alpha" && f > 1 {
+			return FloatColour{},
+				fmt.Errorf("bad rgbf colour %q: the %s value (%q)"+
+					" must be in the range 0.0 to 1.0",
+					s, names[i], v)
+		}
+
+		fv[i] = float32(f)
+	}
+
+	return FloatColour{R: fv[0], G: fv[1], B: fv[2], A: fv[3]}, nil
+}
+
+// FloatRGBA is used to set a floating point colour value. It accepts any
+// of the colour values accepted by the RGB setter and also values of the
+// form rgbf(r, g, b) or rgbf(r, g, b, a) where the values are floating
+// point numbers. The red, green and blue values in an rgbf value may exceed
+// 1.0 (for HDR colours) and are stored exactly as given.
+//
+//nolint:misspell
+type FloatRGBA struct {
+	psetter.ValueReqMandatory
+
+	Value    *FloatColour
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// SRGBEncoded, if set, means that the Value holds sRGB-encoded
+	// channel values. Otherwise colours given by name or in any of the
+	// 8-bit forms are decoded from sRGB into linear values.
+	SRGBEncoded bool
+}
+
+// parser returns the colourParser for this setter
+func (s FloatRGBA) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) either parses
+// the rgbf value or else parses the value as an RGB colour and converts it
+// into floating point values.
+func (s FloatRGBA) SetWithVal(_ string, paramVal string) error {
+	if parts := rgbfRE.FindStringSubmatch(paramVal); parts != nil {
+		fc, err := parseRGBF(paramVal, parts[1])
+		if err == nil {
+			*s.Value = fc
+		}
+
+		return err
+	}
+
+	c, err := s.parser().parseRGBA(paramVal)
+	if err != nil {
+		return err
+	}
+
+	*s.Value = MakeFloatColour(c, !s.SRGBEncoded)
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s FloatRGBA) AllowedValues() string {
+	encoding := "linear"
+	if s.SRGBEncoded {
+		encoding = "sRGB-encoded"
+	}
+
+	return s.parser().allowedValues() +
+		"\n\n" +
+		"Or a floating point colour: rgbf(r, g, b) or rgbf(r, g, b, a)." +
+		" The red, green and blue values must not be negative" +
+		" and may exceed 1.0; the alpha value (default: 1.0)" +
+		" must be in the range 0.0 to 1.0." +
+		" The values are taken as " + encoding + "." +
+		" Colours given in any other form" +
+		" are converted to " + encoding + " values"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s FloatRGBA) ValDescribe() string {
+	return "colour"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s FloatRGBA) CurrentValue() string {
+	return s.Value.String()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder is
+// invalid.
+func (s FloatRGBA) CheckSetter(name string) {
+	intro := name + ": coloursetter.FloatRGBA Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " FloatRGBA.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " FloatRGBA.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " FloatRGBA.ChannelOrder: " + err.Error())
+	}
+}
@@ -0,0 +1,119 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colourtesthelper"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestFloatRGBASetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      FloatRGBA
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("rgbf - HDR"),
+			v:      "rgbf(1.2, 0.5, 0.0, 1.0)",
+			expVal: "rgbf(1.2, 0.5, 0, 1)",
+		},
+		{
+			ID:     testhelper.MkID("rgbf - no alpha"),
+			v:      " RGBF ( 0.25, 0.5, 2 ) ",
+			expVal: "rgbf(0.25, 0.5, 2, 1)",
+		},
+		{
+			ID:     testhelper.MkID("named - linear"),
+			v:      "web:gray",
+			expVal: "rgbf(0.2158605, 0.2158605, 0.2158605, 1)",
+		},
+		{
+			ID:     testhelper.MkID("named - sRGB"),
+			s:      FloatRGBA{SRGBEncoded: true},
+			v:      "web:gray",
+			expVal: "rgbf(0.5019608, 0.5019608, 0.5019608, 1)",
+		},
+		{
+			ID:     testhelper.MkID("hex - linear"),
+			v:      "#ff000080",
+			expVal: "rgbf(1, 0, 0, 0.5019608)",
+		},
+		{
+			ID: testhelper.MkID("rgbf - too few values"),
+			ExpErr: testhelper.MkExpErr(`bad rgbf colour "rgbf(1, 2)":` +
+				` 3 or 4 values expected, 2 found`),
+			v: "rgbf(1, 2)",
+		},
+		{
+			ID: testhelper.MkID("rgbf - negative"),
+			ExpErr: testhelper.MkExpErr(`bad rgbf colour "rgbf(1, -2, 0)":` +
+				` the green value ("-2")` +
+				` must be a finite, non-negative number`),
+			v: "rgbf(1, -2, 0)",
+		},
+		{
+			ID: testhelper.MkID("rgbf - bad alpha"),
+			ExpErr: testhelper.MkExpErr(
+				`bad rgbf colour "rgbf(1, 1, 0, 1.5)":` +
+					` the alpha value ("1.5")` +
+					` must be in the range 0.0 to 1.0`),
+			v: "rgbf(1, 1, 0, 1.5)",
+		},
+		{
+			ID: testhelper.MkID("rgbf - not a number"),
+			ExpErr: testhelper.MkExpErr(`bad rgbf colour "rgbf(1, x, 0)":` +
+				` the green value ("x") is not a number`),
+			v: "rgbf(1, x, 0)",
+		},
+	}
+
+	for _, tc := range testCases {
+		var fc FloatColour
+
+		s := tc.s
+		s.Value = &fc
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestFloatColourRGBA(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		fc     FloatColour
+		linear bool
+		expVal color.RGBA //nolint:misspell
+	}{
+		{
+			ID:     testhelper.MkID("scaled"),
+			fc:     FloatColour{R: 1, G: 0.5019608, B: 0, A: 0.5019608},
+			expVal: color.RGBA{R: 0xff, G: 0x80, B: 0, A: 0x80}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("linear"),
+			fc:     FloatColour{R: 0.2158605, G: 0.2158605, B: 0.2158605, A: 1},
+			linear: true,
+			expVal: color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("HDR, clamped"),
+			fc:     FloatColour{R: 1.2, G: 0.5, B: 0, A: 1},
+			linear: true,
+			expVal: color.RGBA{R: 0xff, G: 0xbc, B: 0, A: 0xff}, //nolint:misspell
+		},
+	}
+
+	for _, tc := range testCases {
+		colourtesthelper.DiffRGBA(t, tc.IDStr(), "colour",
+			tc.fc.ToRGBA(tc.linear), tc.expVal)
+	}
+}
@@ -0,0 +1,181 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// Gray is used to set a standard library color.Gray value. Any colour
+// value accepted by the RGB setter may be given and it is converted to its
+// luma using the Luma coefficients. The alpha channel is ignored.
+//
+//nolint:misspell
+type Gray struct {
+	psetter.ValueReqMandatory
+
+	Value    *color.Gray
+	Families colour.Families
+
+	// Luma gives the weights used to convert the colour to gray. If it is
+	// not set the BT.601 coefficients are used.
+	Luma LumaCoefficients
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+}
+
+// parser returns the colourParser for this setter
+func (s Gray) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+		coefficients: s.Luma,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// colour and sets the Value to its luma.
+func (s Gray) SetWithVal(_ string, paramVal string) error {
+	c, err := s.parser().parseRGBA(paramVal)
+	if err != nil {
+		return err
+	}
+
+	s.Value.Y = clampUint8(s.Luma.luma(c))
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Gray) AllowedValues() string {
+	return s.parser().allowedValues() +
+		"\n\n" +
+		"The colour is converted to gray using the " +
+		s.Luma.String() + " luma coefficients"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Gray) ValDescribe() string {
+	return "colour"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Gray) CurrentValue() string {
+	return fmt.Sprintf("Gray{Y: %#02x}", s.Value.Y)
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the Luma or
+// ChannelOrder is invalid.
+func (s Gray) CheckSetter(name string) {
+	intro := name + ": coloursetter.Gray Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " Gray.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " Gray.Families: " + err.Error())
+	}
+
+	if err := s.Luma.Check(); err != nil {
+		panic(intro + " Gray.Luma: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " Gray.ChannelOrder: " + err.Error())
+	}
+}
+
+// Gray16 is used to set a standard library color.Gray16 value. Any colour
+// value accepted by the RGB setter may be given and it is converted to its
+// luma using the Luma coefficients. The alpha channel is ignored.
+//
+//nolint:misspell
+type Gray16 struct {
+	psetter.ValueReqMandatory
+
+	Value    *color.Gray16
+	Families colour.Families
+
+	// Luma gives the weights used to convert the colour to gray. If it is
+	// not set the BT.601 coefficients are used.
+	Luma LumaCoefficients
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+}
+
+// parser returns the colourParser for this setter
+func (s Gray16) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+		coefficients: s.Luma,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// colour and sets the Value to its luma.
+func (s Gray16) SetWithVal(_ string, paramVal string) error {
+	c, err := s.parser().parseRGBA(paramVal)
+	if err != nil {
+		return err
+	}
+
+	const scale = math.MaxUint16 / math.MaxUint8
+
+	y := math.Round(s.Luma.luma(c) * scale)
+	s.Value.Y = uint16(max(0, min(math.MaxUint16, y)))
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Gray16) AllowedValues() string {
+	return s.parser().allowedValues() +
+		"\n\n" +
+		"The colour is converted to gray using the " +
+		s.Luma.String() + " luma coefficients"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Gray16) ValDescribe() string {
+	return "colour"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Gray16) CurrentValue() string {
+	return fmt.Sprintf("Gray16{Y: %#04x}", s.Value.Y)
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the Luma or
+// ChannelOrder is invalid.
+func (s Gray16) CheckSetter(name string) {
+	intro := name + ": coloursetter.Gray16 Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " Gray16.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " Gray16.Families: " + err.Error())
+	}
+
+	if err := s.Luma.Check(); err != nil {
+		panic(intro + " Gray16.Luma: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " Gray16.ChannelOrder: " + err.Error())
+	}
+}
@@ -0,0 +1,296 @@
+package coloursetter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// harmonyPrefix introduces the colour harmony notation
+const harmonyPrefix = "harmony:"
+
+// HarmonyScheme identifies a scheme for choosing colours which go well with
+// a base colour
+type HarmonyScheme int
+
+// These are the supported colour harmony schemes
+const (
+	// Complementary adds the colour opposite the base colour on the
+	// colour wheel
+	Complementary HarmonyScheme = iota
+	// SplitComplementary adds the two colours either side of the
+	// complementary colour
+	SplitComplementary
+	// Triadic adds the two colours which, with the base colour, are
+	// evenly spaced around the colour wheel
+	Triadic
+	// Tetradic adds the three colours which, with the base colour, are
+	// evenly spaced around the colour wheel (a square scheme)
+	Tetradic
+	// Analogous adds the two colours either side of the base colour
+	Analogous
+)
+
+// AllHarmonySchemes lists all the supported colour harmony schemes
+var AllHarmonySchemes = []HarmonyScheme{
+	Complementary, SplitComplementary, Triadic, Tetradic, Analogous,
+}
+
+// String returns the name of the colour harmony scheme
+func (h HarmonyScheme) String() string {
+	switch h {
+	case Complementary:
+		return "complementary"
+	case SplitComplementary:
+		return "split-complementary"
+	case Triadic:
+		return "triadic"
+	case Tetradic:
+		return "tetradic"
+	case Analogous:
+		return "analogous"
+	}
+
+	return fmt.Sprintf("HarmonyScheme(%d)", int(h))
+}
+
+// Check returns a non-nil error if the HarmonyScheme is not one of the
+// supported values.
+func (h HarmonyScheme) Check() error {
+	if h < Complementary || h > Analogous {
+		return fmt.Errorf("%d is not a valid HarmonyScheme", int(h))
+	}
+
+	return nil
+}
+
+// hueOffsets returns the rotations (in degrees) of the hue of the base
+// colour giving the companion colours
+//
+//nolint:mnd
+func (h HarmonyScheme) hueOffsets() []float64 {
+	switch h {
+	case Complementary:
+		return []float64{180}
+	case SplitComplementary:
+		return []float64{150, 210}
+	case Triadic:
+		return []float64{120, 240}
+	case Tetradic:
+		return []float64{90, 180, 270}
+	case Analogous:
+		return []float64{-30, 30}
+	}
+
+	return nil
+}
+
+// ParseHarmonyScheme returns the HarmonyScheme with the given name. The
+// name is matched case-blind.
+func ParseHarmonyScheme(name string) (HarmonyScheme, error) {
+	lc := strings.ToLower(strings.TrimSpace(name))
+
+	for _, h := range AllHarmonySchemes {
+		if h.String() == lc {
+			return h, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown colour harmony: %q (it must be one of %s)",
+		name, harmonySchemeNames())
+}
+
+// harmonySchemeNames returns the names of the harmony schemes as a
+// comma-separated list
+func harmonySchemeNames() string {
+	names := make([]string, 0, len(AllHarmonySchemes))
+	for _, h := range AllHarmonySchemes {
+		names = append(names, h.String())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Colours returns the base colour followed by its companion colours. The
+// hue of the base colour is rotated in the OKLCH colour space, keeping its
+// lightness and chroma so that the colours are balanced in perceived
+// lightness; if a companion colour would fall outside the sRGB gamut its
+// chroma is reduced. The companion colours have the alpha of the base
+// colour and are named after it with the hue rotation appended (for
+// instance, "navy+120°"). Note that an achromatic (grey) base colour has
+// no hue and so its companion colours are the same as the base colour.
+func (h HarmonyScheme) Colours(base colour.NamedColour) []colour.NamedColour {
+	offsets := h.hueOffsets()
+	bc := base.Colour()
+	ok := MakeOKLab(bc)
+
+	ncs := make([]colour.NamedColour, 0, len(offsets)+1)
+	ncs = append(ncs, base)
+
+	for _, offset := range offsets {
+		hue := ok.Hue() + offset*math.Pi/180 //nolint:mnd
+
+		c := MakeOKLCH(ok.L, ok.Chroma(), hue).ToRGBAInGamut()
+		c.A = bc.A
+
+		ncs = append(ncs,
+			colour.MakeNamedColour(fmt.Sprintf("%s%+g°", base.Name(), offset), c))
+	}
+
+	return ncs
+}
+
+// parseHarmony parses a colour harmony, "scheme(colour)" (with or without
+// a leading "harmony:"), and returns the base colour followed by its
+// companions
+func (p colourParser) parseHarmony(s string) ([]colour.NamedColour, error) {
+	val := strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToLower(val), harmonyPrefix) {
+		val = strings.TrimSpace(val[len(harmonyPrefix):])
+	}
+
+	name, colourStr, found := strings.Cut(val, "(")
+	if !found || !strings.HasSuffix(colourStr, ")") {
+		return nil, fmt.Errorf("bad colour harmony %q: expected %sscheme(colour)",
+			s, harmonyPrefix)
+	}
+
+	h, err := ParseHarmonyScheme(name)
+	if err != nil {
+		return nil, fmt.Errorf("bad colour harmony %q: %w", s, err)
+	}
+
+	base, err := p.parse(strings.TrimSpace(colourStr[:len(colourStr)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("bad colour harmony %q: %w", s, err)
+	}
+
+	return h.Colours(base), nil
+}
+
+// ParseHarmony parses a colour harmony, "scheme(colour)" (with or without
+// a leading "harmony:"), taking the colour from the families, and returns
+// the base colour followed by its companions. The colour may be given in
+// any of the forms accepted by the NamedColour setter.
+func ParseHarmony(fl colour.Families, s string) ([]colour.NamedColour, error) {
+	return colourParser{families: fl}.parseHarmony(s)
+}
+
+// harmonyAllowedValues describes the colour harmony notation
+func harmonyAllowedValues() string {
+	return harmonyPrefix + "scheme(colour) giving the colour" +
+		" and the colours which go well with it" +
+		" (for instance, " + harmonyPrefix + Triadic.String() + "(navy))." +
+		" The scheme is one of " + harmonySchemeNames()
+}
+
+// Harmony is used to set a list of colours forming a colour harmony: a
+// base colour and its companion colours, chosen by rotating the hue of the
+// base colour. The value is a scheme name followed by the base colour in
+// brackets, optionally preceded by harmony: (for instance,
+// harmony:triadic(navy)).
+//
+//nolint:misspell
+type Harmony struct {
+	psetter.ValueReqMandatory
+
+	Value    *[]colour.NamedColour
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// CVDMinDeltaE, if greater than zero, causes the harmony to be
+	// rejected if any two of its colours, as seen by someone with
+	// protanopia, deuteranopia or tritanopia, differ by less than this
+	// (CIEDE2000) Delta E.
+	CVDMinDeltaE float64
+	// CVD, if set, has the colours transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+}
+
+// parser returns the colourParser for this setter
+func (s Harmony) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) generates the
+// colour harmony and, if it is valid, sets the Value.
+func (s Harmony) SetWithVal(_ string, paramVal string) error {
+	ncs, err := s.parser().parseHarmony(paramVal)
+	if err != nil {
+		return err
+	}
+
+	if s.CVDMinDeltaE > 0 {
+		if err := checkCVDDistinct(namedCVDColours(ncs),
+			s.CVDMinDeltaE); err != nil {
+			return err
+		}
+	}
+
+	*s.Value = ncs
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Harmony) AllowedValues() string {
+	return "a colour harmony given as " + harmonyAllowedValues() +
+		". The leading " + harmonyPrefix + " may be omitted" +
+		cvdAllowedValues("the colours", s.CVDMinDeltaE) +
+		"\n\n" +
+		"The colour is given as follows. " + s.parser().allowedValues()
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Harmony) ValDescribe() string {
+	return "scheme(colour)"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Harmony) CurrentValue() string {
+	vals := make([]string, 0, len(*s.Value))
+	for _, nc := range *s.Value {
+		vals = append(vals, nc.Name()+"="+ChannelOrderRGBA.Hex(nc.Colour()))
+	}
+
+	return strings.Join(vals, ", ")
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder is
+// invalid or the CVDMinDeltaE is negative. If the CVD is set the Value is
+// registered with it.
+func (s Harmony) CheckSetter(name string) {
+	intro := name + ": coloursetter.Harmony Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " Harmony.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " Harmony.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " Harmony.ChannelOrder: " + err.Error())
+	}
+
+	if s.CVDMinDeltaE < 0 {
+		panic(fmt.Sprintf("%s Harmony.CVDMinDeltaE: %g is negative",
+			intro, s.CVDMinDeltaE))
+	}
+
+	s.CVD.registerNamedColours(s.Value)
+}
@@ -0,0 +1,142 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"math"
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestHarmonySetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      Harmony
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("triadic"),
+			v:      "harmony:triadic(navy)",
+			expVal: "navy=#000080ff, navy+120°=#4d070cff, navy+240°=#053008ff",
+		},
+		{
+			ID: testhelper.MkID("complementary, no prefix, mixed case"),
+			v:  "Complementary(#ff8800)",
+			expVal: "#ff8800=#ff8800ff," +
+				" #ff8800+180°=#33b9fcff",
+		},
+		{
+			ID: testhelper.MkID("tetradic"),
+			v:  "harmony: tetradic(red)",
+			expVal: "red=#ff0000ff, red+90°=#80931fff," +
+				" red+180°=#2499a9ff, red+270°=#a05afdff",
+		},
+		{
+			ID: testhelper.MkID("split-complementary, grey"),
+			v:  "split-complementary(grey)",
+			expVal: "grey=#808080ff, grey+150°=#808080ff," +
+				" grey+210°=#808080ff",
+		},
+		{
+			ID: testhelper.MkID("bad scheme"),
+			ExpErr: testhelper.MkExpErr(`bad colour harmony "foo(red)":` +
+				` unknown colour harmony: "foo" (it must be one of` +
+				" complementary, split-complementary, triadic," +
+				" tetradic, analogous)"),
+			v: "foo(red)",
+		},
+		{
+			ID: testhelper.MkID("bad colour"),
+			ExpErr: testhelper.MkExpErr(
+				`bad colour harmony "triadic(nonesuch)":`, `"nonesuch"`),
+			v: "triadic(nonesuch)",
+		},
+		{
+			ID: testhelper.MkID("no brackets"),
+			ExpErr: testhelper.MkExpErr(`bad colour harmony "triadic red":` +
+				" expected harmony:scheme(colour)"),
+			v: "triadic red",
+		},
+		{
+			ID: testhelper.MkID("hard to tell apart"),
+			ExpErr: testhelper.MkExpErr(`the colours "red" and "red+30°"` +
+				" are hard to tell apart with deuteranopia"),
+			s: Harmony{CVDMinDeltaE: 10},
+			v: "analogous(red)",
+		},
+		{
+			ID:     testhelper.MkID("easy to tell apart"),
+			s:      Harmony{CVDMinDeltaE: 10},
+			v:      "complementary(red)",
+			expVal: "red=#ff0000ff, red+180°=#2499a9ff",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v []colour.NamedColour
+
+		s := tc.s
+		s.Value = &v
+		err := s.SetWithVal("", tc.v)
+
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "value",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestHarmonyColours(t *testing.T) {
+	const epsilon = 0.01
+
+	base := colour.MakeNamedColour("orange",
+		color.RGBA{R: 0xe0, G: 0x80, B: 0x30, A: 0x80}) //nolint:misspell
+	baseOK := MakeOKLab(base.Colour())
+
+	for _, h := range AllHarmonySchemes {
+		ncs := h.Colours(base)
+		if len(ncs) != len(h.hueOffsets())+1 {
+			t.Errorf("%s: %d colours, expected %d",
+				h, len(ncs), len(h.hueOffsets())+1)
+		}
+
+		if ncs[0] != base {
+			t.Errorf("%s: the first colour should be the base colour", h)
+		}
+
+		for _, nc := range ncs[1:] {
+			ok := MakeOKLab(nc.Colour())
+			if math.Abs(ok.L-baseOK.L) > epsilon {
+				t.Errorf("%s: %s has lightness %.3f, expected %.3f",
+					h, nc.Name(), ok.L, baseOK.L)
+			}
+
+			if nc.Colour().A != base.Colour().A {
+				t.Errorf("%s: %s has a different alpha from the base colour",
+					h, nc.Name())
+			}
+		}
+	}
+
+	if err := HarmonyScheme(99).Check(); err == nil {
+		t.Error("an invalid HarmonyScheme should fail the Check")
+	}
+}
+
+func TestParseHarmony(t *testing.T) {
+	ncs, err := ParseHarmony(colour.Families{}, "analogous(#336699)")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+
+	names := []string{}
+	for _, nc := range ncs {
+		names = append(names, nc.Name())
+	}
+
+	testhelper.DiffStringSlice(t, "analogous(#336699)", "names", names,
+		[]string{"#336699", "#336699-30°", "#336699+30°"})
+}
@@ -0,0 +1,312 @@
+package coloursetter
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"image/color" //nolint:misspell
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// These are the prefixes used by the ColourHash setter and the default
+// lightness and chroma of the OKLCH ring
+const (
+	hashPrefix     = "hash:"
+	hashRingPrefix = "oklch("
+
+	DfltHashRingL = 0.7
+	DfltHashRingC = 0.12
+)
+
+// ColourHasher maps arbitrary strings (such as user names, host names or
+// log sources) to colours. The same key always gives the same colour. If
+// the Palette is not empty the colour is chosen from it, otherwise it is
+// chosen from a ring of hues in the OKLCH colour space having the given
+// lightness (L) and chroma (C). If both L and C are zero the default
+// lightness and chroma are used.
+type ColourHasher struct {
+	Palette []color.RGBA //nolint:misspell
+	L, C    float64
+}
+
+// keyHash returns the hash of the key
+func keyHash(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return h.Sum64()
+}
+
+// hueOf returns the hue (in degrees) corresponding to the hash
+func hueOf(h uint64) float64 {
+	const fullCircle = 360
+
+	return float64(h) / (float64(math.MaxUint64) + 1) * fullCircle
+}
+
+// ring returns the lightness and chroma of the OKLCH ring
+func (ch ColourHasher) ring() (float64, float64) {
+	if ch.L == 0 && ch.C == 0 {
+		return DfltHashRingL, DfltHashRingC
+	}
+
+	return ch.L, ch.C
+}
+
+// ringColour returns the colour on the OKLCH ring at the given hue (in
+// degrees). If the colour is outside the sRGB gamut the chroma is reduced
+// until it is within the gamut.
+func (ch ColourHasher) ringColour(hue float64) color.RGBA { //nolint:misspell
+	l, chroma := ch.ring()
+
+	return MakeOKLCH(l, chroma, hue*math.Pi/180).ToRGBAInGamut() //nolint:mnd
+}
+
+// Colour returns the colour for the key
+func (ch ColourHasher) Colour(key string) color.RGBA { //nolint:misspell
+	h := keyHash(key)
+
+	if len(ch.Palette) > 0 {
+		return ch.Palette[h%uint64(len(ch.Palette))]
+	}
+
+	return ch.ringColour(hueOf(h))
+}
+
+// AssignColours returns colours for all of the keys, avoiding giving the
+// same colour to two keys where possible. If there are no more keys than
+// colours in the Palette each key is given a different colour; with the
+// OKLCH ring the hues are evenly spaced. Note that, unlike Colour, the
+// colour given to a key depends on the other keys but it is always the same
+// for the same set of keys.
+func (ch ColourHasher) AssignColours(keys []string) map[string]color.RGBA { //nolint:misspell
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	// process the keys in order of their hash so that the result does not
+	// depend on the order in which the keys are given
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(keyHash(a), keyHash(b)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	colours := make(map[string]color.RGBA, len(keys)) //nolint:misspell
+
+	if len(keys) == 0 {
+		return colours
+	}
+
+	if len(ch.Palette) == 0 {
+		start := hueOf(keyHash(keys[0]))
+		step := 360 / float64(len(keys)) //nolint:mnd
+
+		for i, k := range keys {
+			colours[k] = ch.ringColour(math.Mod(start+float64(i)*step, 360)) //nolint:mnd
+		}
+
+		return colours
+	}
+
+	n := uint64(len(ch.Palette))
+	used := make([]bool, n)
+	usedCount := uint64(0)
+
+	for _, k := range keys {
+		idx := keyHash(k) % n
+
+		if usedCount < n {
+			for used[idx] {
+				idx = (idx + 1) % n
+			}
+
+			used[idx] = true
+			usedCount++
+		}
+
+		colours[k] = ch.Palette[idx]
+	}
+
+	return colours
+}
+
+// String returns a description of the ColourHasher
+func (ch ColourHasher) String() string {
+	if len(ch.Palette) == 0 {
+		l, c := ch.ring()
+
+		return fmt.Sprintf("%s%g, %g)", hashRingPrefix, l, c)
+	}
+
+	hexVals := []string{}
+
+	for i, c := range ch.Palette {
+		if i == paletteMaxShown {
+			hexVals = append(hexVals, "...")
+			break
+		}
+
+		hexVals = append(hexVals, ChannelOrderRGBA.Hex(c))
+	}
+
+	return fmt.Sprintf("%d colours: %s",
+		len(ch.Palette), strings.Join(hexVals, ","))
+}
+
+// parseHashRing parses the OKLCH ring lightness and chroma
+func parseHashRing(s string) (ColourHasher, error) {
+	args := strings.TrimSpace(s)
+	if !strings.HasSuffix(args, ")") {
+		return ColourHasher{},
+			fmt.Errorf("bad OKLCH ring %q: no trailing \")\"", s)
+	}
+
+	parts := strings.Split(args[len(hashRingPrefix):len(args)-1], ",")
+	if len(parts) != 2 { //nolint:mnd
+		return ColourHasher{},
+			fmt.Errorf("bad OKLCH ring %q:"+
+				" 2 values (lightness and chroma) expected, %d found",
+				s, len(parts))
+	}
+
+	l, errL := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	c, errC := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+
+	if errL != nil || errC != nil || l < 0 || l > 1 || c < 0 {
+		return ColourHasher{},
+			fmt.Errorf("bad OKLCH ring %q:"+
+				" the lightness must be from 0.0 to 1.0"+
+				" and the chroma must not be negative", s)
+	}
+
+	return ColourHasher{L: l, C: c}, nil
+}
+
+// ColourHash is used to set a ColourHasher. The value (optionally preceded
+// by hash:) is either an OKLCH ring, oklch(L, C), or a list of colours as
+// accepted by the Palette setter or a ColorBrewer scheme (such as
+// brewer:Set2:8).
+//
+//nolint:misspell
+type ColourHash struct {
+	psetter.ValueReqMandatory
+
+	Value    *ColourHasher
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+}
+
+// palette returns the Palette setter used to parse a list of colours
+func (s ColourHash) palette(v *color.Palette) Palette { //nolint:misspell
+	return Palette{
+		Value:        v,
+		Families:     s.Families,
+		ChannelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// ring or the palette and, if it is valid, sets the Value.
+func (s ColourHash) SetWithVal(_ string, paramVal string) error {
+	val := strings.TrimSpace(paramVal)
+	if strings.HasPrefix(strings.ToLower(val), hashPrefix) {
+		val = strings.TrimSpace(val[len(hashPrefix):])
+	}
+
+	lc := strings.ToLower(val)
+
+	if strings.HasPrefix(lc, hashRingPrefix) {
+		ch, err := parseHashRing(val)
+		if err == nil {
+			*s.Value = ch
+		}
+
+		return err
+	}
+
+	if strings.HasPrefix(lc, colormapBrewerPrefix) {
+		g, err := ParseColormap(val)
+		if err != nil {
+			return err
+		}
+
+		*s.Value = ColourHasher{Palette: g.Colours(0)}
+
+		return nil
+	}
+
+	var p color.Palette //nolint:misspell
+	if err := s.palette(&p).SetWithVal("", val); err != nil {
+		return err
+	}
+
+	ch := ColourHasher{Palette: make([]color.RGBA, 0, len(p))} //nolint:misspell
+	for _, c := range p {
+		ch.Palette = append(ch.Palette,
+			color.RGBAModel.Convert(c).(color.RGBA)) //nolint:misspell,forcetypeassert
+	}
+
+	*s.Value = ch
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s ColourHash) AllowedValues() string {
+	var p color.Palette //nolint:misspell
+
+	return "the colours from which a colour is chosen for each key," +
+		" optionally preceded by " + hashPrefix + "." +
+		" This is either " + hashRingPrefix + "L, C)" +
+		" for a ring of hues in the OKLCH colour space" +
+		" with lightness L (0.0 to 1.0, typically " +
+		strconv.FormatFloat(DfltHashRingL, 'g', -1, 64) + ")" +
+		" and chroma C (typically " +
+		strconv.FormatFloat(DfltHashRingC, 'g', -1, 64) + ")" +
+		" or " + colormapBrewerPrefix + "Scheme:N" +
+		" for the colours of a ColorBrewer scheme" +
+		" or else " + s.palette(&p).AllowedValues()
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s ColourHash) ValDescribe() string {
+	return "colours"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s ColourHash) CurrentValue() string {
+	return s.Value.String()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder is
+// invalid.
+func (s ColourHash) CheckSetter(name string) {
+	intro := name + ": coloursetter.ColourHash Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " ColourHash.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " ColourHash.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " ColourHash.ChannelOrder: " + err.Error())
+	}
+}
@@ -0,0 +1,132 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestColourHashSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("ring"),
+			v:      "hash:oklch(0.6, 0.1)",
+			expVal: "oklch(0.6, 0.1)",
+		},
+		{
+			ID:     testhelper.MkID("ring - no prefix"),
+			v:      "OKLCH(0.8,0.05)",
+			expVal: "oklch(0.8, 0.05)",
+		},
+		{
+			ID:     testhelper.MkID("brewer"),
+			v:      "hash:brewer:Dark2:3",
+			expVal: "3 colours: #1b9e77ff,#d95f02ff,#7570b3ff",
+		},
+		{
+			ID:     testhelper.MkID("palette"),
+			v:      "hash:red,#00f",
+			expVal: "2 colours: #ff0000ff,#0000ffff",
+		},
+		{
+			ID: testhelper.MkID("bad ring - count"),
+			ExpErr: testhelper.MkExpErr(`bad OKLCH ring "oklch(0.6)":` +
+				" 2 values (lightness and chroma) expected, 1 found"),
+			v: "hash:oklch(0.6)",
+		},
+		{
+			ID: testhelper.MkID("bad ring - lightness"),
+			ExpErr: testhelper.MkExpErr(`bad OKLCH ring "oklch(2, 0.1)":` +
+				" the lightness must be from 0.0 to 1.0" +
+				" and the chroma must not be negative"),
+			v: "oklch(2, 0.1)",
+		},
+		{
+			ID:     testhelper.MkID("bad ring - no close bracket"),
+			ExpErr: testhelper.MkExpErr(`bad OKLCH ring "oklch(0.5, 0.1":`),
+			v:      "oklch(0.5, 0.1",
+		},
+		{
+			ID:     testhelper.MkID("bad palette"),
+			ExpErr: testhelper.MkExpErr(`bad palette entry 2 ("nonesuch")`),
+			v:      "hash:red,nonesuch",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v ColourHasher
+
+		s := ColourHash{Value: &v}
+		err := s.SetWithVal("", tc.v)
+
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "value",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestColourHasher(t *testing.T) {
+	palette := []color.RGBA{ //nolint:misspell
+		{R: 0xff, A: 0xff},
+		{G: 0xff, A: 0xff},
+		{B: 0xff, A: 0xff},
+	}
+	keys := []string{"alice", "bob", "carol"}
+
+	for _, ch := range []ColourHasher{
+		{},
+		{L: 0.5, C: 0.3},
+		{Palette: palette},
+	} {
+		id := ch.String()
+
+		for _, k := range keys {
+			if ch.Colour(k) != ch.Colour(k) {
+				t.Errorf("%s: the colour for %q is not stable", id, k)
+			}
+		}
+
+		assigned := ch.AssignColours(append(keys, "bob"))
+		if len(assigned) != len(keys) {
+			t.Errorf("%s: %d keys assigned colours, expected %d",
+				id, len(assigned), len(keys))
+		}
+
+		seen := map[color.RGBA]string{} //nolint:misspell
+		for k, c := range assigned {
+			if prev, ok := seen[c]; ok {
+				t.Errorf("%s: %q and %q have the same colour", id, prev, k)
+			}
+
+			seen[c] = k
+		}
+
+		reordered := ch.AssignColours([]string{"carol", "alice", "bob"})
+		for k, c := range assigned {
+			if reordered[k] != c {
+				t.Errorf("%s: the colour for %q depends on the key order",
+					id, k)
+			}
+		}
+	}
+
+	if c := (ColourHasher{Palette: palette}).Colour("x"); c.A != 0xff {
+		t.Errorf("the colour should come from the palette: %v", c)
+	}
+
+	ring := ColourHasher{L: 0.7, C: 0.12}
+	for _, k := range keys {
+		ok := MakeOKLab(ring.Colour(k))
+		if ok.L < 0.69 || ok.L > 0.71 {
+			t.Errorf("the ring colour for %q has lightness %g, expected 0.7",
+				k, ok.L)
+		}
+	}
+}
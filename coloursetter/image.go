@@ -0,0 +1,46 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"  //nolint:misspell
+	_ "image/gif"  // register the GIF decoder
+	_ "image/jpeg" // register the JPEG decoder
+	_ "image/png"  // register the PNG decoder
+	"os"
+)
+
+// loadImage reads and decodes the image in the named file. The image may be
+// in any of the PNG, JPEG or GIF formats.
+func loadImage(fName string) (image.Image, error) {
+	f, err := os.Open(fName) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the image file: %w", err)
+	}
+
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, fmt.Errorf("cannot decode the image file %q:"+
+				" the format is not supported (use PNG, JPEG or GIF)",
+				fName)
+		}
+
+		return nil, fmt.Errorf("cannot decode the image file %q: %w",
+			fName, err)
+	}
+
+	return img, nil
+}
+
+// pixelRGBA returns the colour of the image at the given point as a
+// non-alpha-premultiplied RGBA value. This matches the way that colours are
+// given elsewhere in this package.
+func pixelRGBA(img image.Image, x, y int) color.RGBA { //nolint:misspell
+	nc := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA) //nolint:misspell,forcetypeassert
+
+	return color.RGBA{R: nc.R, G: nc.G, B: nc.B, A: nc.A} //nolint:misspell
+}
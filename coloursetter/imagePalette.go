@@ -0,0 +1,503 @@
+package coloursetter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"image"
+	"image/color" //nolint:misspell
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// These are the default and maximum number of colours that the
+// ImagePalette setter will extract from an image
+const (
+	DfltImagePaletteCount = 5
+	MaxImagePaletteCount  = 256
+
+	kMeansMaxIterations = 50
+)
+
+// ExtractionMethod identifies the algorithm used to find the dominant
+// colours in an image
+type ExtractionMethod int
+
+// These are the available extraction methods
+const (
+	// MedianCut repeatedly splits the box of colours with the widest
+	// range of values at its median
+	MedianCut ExtractionMethod = iota
+	// KMeans clusters the colours using Lloyd's algorithm starting from
+	// the median-cut colours
+	KMeans
+)
+
+// String returns the name of the extraction method
+func (em ExtractionMethod) String() string {
+	switch em {
+	case MedianCut:
+		return "median-cut"
+	case KMeans:
+		return "k-means"
+	}
+
+	return fmt.Sprintf("ExtractionMethod(%d)", int(em))
+}
+
+// Check returns a non-nil error if the ExtractionMethod is not one of the
+// supported values.
+func (em ExtractionMethod) Check() error {
+	if em < MedianCut || em > KMeans {
+		return fmt.Errorf("%d is not a valid ExtractionMethod", int(em))
+	}
+
+	return nil
+}
+
+// colourCount records a colour and the number of pixels having it
+type colourCount struct {
+	c color.RGBA //nolint:misspell
+	n int
+}
+
+// channel returns the value of the i'th channel (red, green, blue) of c
+func channel(c color.RGBA, i int) uint8 { //nolint:misspell
+	switch i {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
+
+// imageHistogram returns the distinct colours in the image with their pixel
+// counts, sorted by colour. Fully transparent pixels are ignored and the
+// alpha value of the others is discarded.
+func imageHistogram(img image.Image) []colourCount {
+	counts := map[color.RGBA]int{} //nolint:misspell
+	b := img.Bounds()
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := pixelRGBA(img, x, y)
+			if c.A == 0 {
+				continue
+			}
+
+			c.A = math.MaxUint8
+			counts[c]++
+		}
+	}
+
+	hist := make([]colourCount, 0, len(counts))
+	for c, n := range counts {
+		hist = append(hist, colourCount{c: c, n: n})
+	}
+
+	slices.SortFunc(hist, func(a, b colourCount) int {
+		return colour.RGBACompare(a.c, b.c)
+	})
+
+	return hist
+}
+
+// weightedMean returns the pixel count and the mean colour of the entries
+func weightedMean(ccs []colourCount) colourCount {
+	var total, r, g, b float64
+
+	for _, cc := range ccs {
+		w := float64(cc.n)
+		total += w
+		r += w * float64(cc.c.R)
+		g += w * float64(cc.c.G)
+		b += w * float64(cc.c.B)
+	}
+
+	return colourCount{
+		c: color.RGBA{ //nolint:misspell
+			R: clampUint8(r / total),
+			G: clampUint8(g / total),
+			B: clampUint8(b / total),
+			A: math.MaxUint8,
+		},
+		n: int(total),
+	}
+}
+
+// widestChannel returns the channel with the widest range of values in the
+// entries and the size of that range
+func widestChannel(ccs []colourCount) (int, int) {
+	bestCh, bestRange := 0, -1
+
+	for ch := range 3 {
+		lo, hi := math.MaxUint8, 0
+
+		for _, cc := range ccs {
+			v := int(channel(cc.c, ch))
+			lo = min(lo, v)
+			hi = max(hi, v)
+		}
+
+		if hi-lo > bestRange {
+			bestCh, bestRange = ch, hi-lo
+		}
+	}
+
+	return bestCh, bestRange
+}
+
+// sortDominant sorts the colours by descending pixel count and then by
+// colour so that the results are deterministic
+func sortDominant(ccs []colourCount) {
+	slices.SortFunc(ccs, func(a, b colourCount) int {
+		if c := cmp.Compare(b.n, a.n); c != 0 {
+			return c
+		}
+
+		return colour.RGBACompare(a.c, b.c)
+	})
+}
+
+// medianCut returns up to n colours representing the histogram, found by
+// the median-cut algorithm, most dominant first
+func medianCut(hist []colourCount, n int) []colourCount {
+	boxes := [][]colourCount{hist}
+
+	for len(boxes) < n {
+		idx, ch, widest := -1, 0, 0
+
+		for i, box := range boxes {
+			if len(box) < 2 { //nolint:mnd
+				continue
+			}
+
+			if boxCh, r := widestChannel(box); r > widest {
+				idx, ch, widest = i, boxCh, r
+			}
+		}
+
+		if idx < 0 {
+			break
+		}
+
+		box := slices.Clone(boxes[idx])
+		slices.SortStableFunc(box, func(a, b colourCount) int {
+			return cmp.Compare(channel(a.c, ch), channel(b.c, ch))
+		})
+
+		total := 0
+		for _, cc := range box {
+			total += cc.n
+		}
+
+		split, sum := 1, 0
+		for i, cc := range box[:len(box)-1] {
+			sum += cc.n
+			split = i + 1
+
+			if 2*sum >= total { //nolint:mnd
+				break
+			}
+		}
+
+		boxes[idx] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+
+	results := make([]colourCount, 0, len(boxes))
+	for _, box := range boxes {
+		results = append(results, weightedMean(box))
+	}
+
+	sortDominant(results)
+
+	return results
+}
+
+// kMeans returns up to n colours representing the histogram, found by
+// k-means clustering starting from the median-cut colours, most dominant
+// first
+func kMeans(hist []colourCount, n int) []colourCount {
+	centres := medianCut(hist, n)
+	assignment := make([]int, len(hist))
+
+	for range kMeansMaxIterations {
+		changed := false
+
+		for i, cc := range hist {
+			best, bestDist := 0, math.MaxInt
+
+			for j, centre := range centres {
+				if d := distSquared(cc.c, centre.c); d < bestDist {
+					best, bestDist = j, d
+				}
+			}
+
+			if assignment[i] != best {
+				assignment[i] = best
+				changed = true
+			}
+		}
+
+		clusters := make([][]colourCount, len(centres))
+		for i, cc := range hist {
+			clusters[assignment[i]] = append(clusters[assignment[i]], cc)
+		}
+
+		for j, cluster := range clusters {
+			if len(cluster) > 0 {
+				centres[j] = weightedMean(cluster)
+			} else {
+				centres[j].n = 0
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	centres = slices.DeleteFunc(centres,
+		func(cc colourCount) bool { return cc.n == 0 })
+	sortDominant(centres)
+
+	return centres
+}
+
+// distSquared returns the square of the Euclidean distance between the two
+// colours in the RGB colour cube. The alpha value is ignored.
+func distSquared(a, b color.RGBA) int { //nolint:misspell
+	rd := int(a.R) - int(b.R)
+	gd := int(a.G) - int(b.G)
+	bd := int(a.B) - int(b.B)
+
+	return rd*rd + gd*gd + bd*bd
+}
+
+// ExtractColours returns up to n dominant colours from the image using the
+// given method, most dominant first. The results are deterministic for a
+// given image. Fully transparent pixels are ignored.
+func ExtractColours(img image.Image, n int, em ExtractionMethod,
+) []color.RGBA { //nolint:misspell
+	hist := imageHistogram(img)
+	if len(hist) == 0 || n <= 0 {
+		return []color.RGBA{} //nolint:misspell
+	}
+
+	var ccs []colourCount
+
+	if em == KMeans {
+		ccs = kMeans(hist, n)
+	} else {
+		ccs = medianCut(hist, n)
+	}
+
+	colours := make([]color.RGBA, 0, len(ccs)) //nolint:misspell
+	for _, cc := range ccs {
+		colours = append(colours, cc.c)
+	}
+
+	return colours
+}
+
+// nearestNamedColour returns the colour from the families which is nearest
+// to the given colour, named with its family and colour name.
+func nearestNamedColour(fl colour.Families, c color.RGBA, //nolint:misspell
+) (colour.NamedColour, error) {
+	fcs, err := fl.ClosestN(c, 1)
+	if err != nil {
+		return colour.NamedColour{}, err
+	}
+
+	if len(fcs) == 0 || len(fcs[0].CNames) == 0 {
+		return colour.NamedColour{}, errors.New("no named colours were found")
+	}
+
+	names := slices.Sorted(slices.Values(fcs[0].CNames))
+
+	return colour.MakeNamedColour(fcs[0].Family.Name()+":"+names[0],
+		fcs[0].Colour), nil
+}
+
+// familiesDesc returns a description of the colour families
+func familiesDesc(fl colour.Families) string {
+	if len(fl) == 0 {
+		return "the standard colour-name families"
+	}
+
+	return "the colour-name families: " + fl.String()
+}
+
+// ImagePalette is used to set a list of colours extracted from an image. The
+// value is the name of an image file (PNG, JPEG or GIF) optionally followed
+// by a colon (:) and the number of colours to extract. The colours are
+// named with their hexadecimal value unless SnapToNamed is set in which case
+// each is replaced by the nearest named colour in the Families.
+type ImagePalette struct {
+	psetter.ValueReqMandatory
+
+	Value    *[]colour.NamedColour
+	Families colour.Families
+
+	// Method gives the algorithm used to find the dominant colours. If it
+	// is not set the median-cut algorithm is used.
+	Method ExtractionMethod
+	// SnapToNamed, if set, causes each extracted colour to be replaced by
+	// its nearest named colour in the Families.
+	SnapToNamed bool
+	// CVDMinDeltaE, if greater than zero, causes the extracted colours to
+	// be rejected if any two of them, as seen by someone with protanopia,
+	// deuteranopia or tritanopia, differ by less than this (CIEDE2000)
+	// Delta E.
+	CVDMinDeltaE float64
+}
+
+// parseImagePaletteVal splits the parameter value into the image file name
+// and the count of colours to extract
+func parseImagePaletteVal(paramVal string) (string, int, error) {
+	fName, countStr, found := cutLast(paramVal, ":")
+	if !found {
+		return paramVal, DfltImagePaletteCount, nil
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil {
+		// not a count so treat the whole value as the file name
+		return paramVal, DfltImagePaletteCount, nil //nolint:nilerr
+	}
+
+	if count < 1 || count > MaxImagePaletteCount {
+		return fName, count,
+			fmt.Errorf("bad colour count (%d): it must be from 1 to %d",
+				count, MaxImagePaletteCount)
+	}
+
+	return fName, count, nil
+}
+
+// cutLast slices s around the last instance of sep, returning the text
+// before and after sep. If sep does not appear in s, cutLast returns s, ""
+// and false.
+func cutLast(s, sep string) (string, string, bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+// SetWithVal (called with the value following the parameter) reads the
+// image and sets the Value to the dominant colours.
+func (s ImagePalette) SetWithVal(_ string, paramVal string) error {
+	fName, count, err := parseImagePaletteVal(paramVal)
+	if err != nil {
+		return err
+	}
+
+	img, err := loadImage(fName)
+	if err != nil {
+		return err
+	}
+
+	colours := ExtractColours(img, count, s.Method)
+	if len(colours) == 0 {
+		return fmt.Errorf("no colours were found in the image file %q", fName)
+	}
+
+	ncs := make([]colour.NamedColour, 0, len(colours))
+	seen := map[string]bool{}
+
+	for _, c := range colours {
+		nc := colour.MakeNamedColour(ChannelOrderRGB.Hex(c), c)
+
+		if s.SnapToNamed {
+			nc, err = nearestNamedColour(s.Families, c)
+			if err != nil {
+				return err
+			}
+		}
+
+		if seen[nc.Name()] {
+			continue
+		}
+
+		seen[nc.Name()] = true
+		ncs = append(ncs, nc)
+	}
+
+	if s.CVDMinDeltaE > 0 {
+		if err := checkCVDDistinct(namedCVDColours(ncs),
+			s.CVDMinDeltaE); err != nil {
+			return err
+		}
+	}
+
+	*s.Value = ncs
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s ImagePalette) AllowedValues() string {
+	snap := ""
+	if s.SnapToNamed {
+		snap = ". Each colour is replaced by the nearest named colour" +
+			" in " + familiesDesc(s.Families)
+	}
+
+	return "the name of an image file (PNG, JPEG or GIF)" +
+		" optionally followed by a colon (:) and the number of colours" +
+		" to extract (from 1 to " + strconv.Itoa(MaxImagePaletteCount) +
+		", default: " + strconv.Itoa(DfltImagePaletteCount) + ")." +
+		" The dominant colours are found using the " +
+		s.Method.String() + " algorithm" + snap +
+		cvdAllowedValues("the colours", s.CVDMinDeltaE)
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s ImagePalette) ValDescribe() string {
+	return "image-file[:count]"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s ImagePalette) CurrentValue() string {
+	names := make([]string, 0, len(*s.Value))
+	for _, nc := range *s.Value {
+		names = append(names, nc.Name())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the Method is
+// invalid or the CVDMinDeltaE is negative.
+func (s ImagePalette) CheckSetter(name string) {
+	intro := name + ": coloursetter.ImagePalette Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " ImagePalette.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " ImagePalette.Families: " + err.Error())
+	}
+
+	if err := s.Method.Check(); err != nil {
+		panic(intro + " ImagePalette.Method: " + err.Error())
+	}
+
+	if s.CVDMinDeltaE < 0 {
+		panic(fmt.Sprintf("%s ImagePalette.CVDMinDeltaE: %g is negative",
+			intro, s.CVDMinDeltaE))
+	}
+}
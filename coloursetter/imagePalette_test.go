@@ -0,0 +1,147 @@
+package coloursetter
+
+import (
+	"image"
+	"image/color" //nolint:misspell
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// mkTestImage creates a PNG image in a temporary directory and returns the
+// file name. The image has horizontal stripes of maroon (6 rows), a colour
+// close to maroon (2 rows), navy (4 rows), near-white (2 rows) and a
+// transparent row which should be ignored.
+func mkTestImage(t *testing.T) string {
+	t.Helper()
+
+	stripes := []struct {
+		c    color.NRGBA //nolint:misspell
+		rows int
+	}{
+		{color.NRGBA{R: 0x80, A: 0xff}, 6},                   //nolint:misspell
+		{color.NRGBA{R: 0x82, G: 0x02, A: 0xff}, 2},          //nolint:misspell
+		{color.NRGBA{B: 0x80, A: 0xff}, 4},                   //nolint:misspell
+		{color.NRGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}, 2}, //nolint:misspell
+		{color.NRGBA{R: 0x12, G: 0x34, B: 0x56}, 1},          //nolint:misspell
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 15))
+	y := 0
+
+	for _, s := range stripes {
+		for range s.rows {
+			for x := range 4 {
+				img.SetNRGBA(x, y, s.c)
+			}
+
+			y++
+		}
+	}
+
+	fName := filepath.Join(t.TempDir(), "test.png")
+
+	f, err := os.Create(fName)
+	if err != nil {
+		t.Fatal("cannot create the test image: ", err)
+	}
+
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal("cannot write the test image: ", err)
+	}
+
+	return fName
+}
+
+func TestImagePaletteSetWithVal(t *testing.T) {
+	fName := mkTestImage(t)
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      ImagePalette
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("median-cut, 3 colours"),
+			v:      fName + ":3",
+			expVal: "#4d0033, #820200, #f0f0f0",
+		},
+		{
+			ID:     testhelper.MkID("median-cut, 2 colours"),
+			v:      fName + ":2",
+			expVal: "#4d0033, #b97978",
+		},
+		{
+			ID:     testhelper.MkID("k-means, 2 colours"),
+			s:      ImagePalette{Method: KMeans},
+			v:      fName + ":2",
+			expVal: "#56002b, #f0f0f0",
+		},
+		{
+			ID:     testhelper.MkID("default count"),
+			v:      fName,
+			expVal: "#800000, #000080, #820200, #f0f0f0",
+		},
+		{
+			ID: testhelper.MkID("snapped"),
+			s: ImagePalette{
+				SnapToNamed: true,
+				Families:    colour.Families{colour.WebColours},
+			},
+			v:      fName,
+			expVal: "web:maroon, web:navy, web:white",
+		},
+		{
+			ID: testhelper.MkID("bad count"),
+			ExpErr: testhelper.MkExpErr(
+				"bad colour count (0): it must be from 1 to 256"),
+			v: fName + ":0",
+		},
+		{
+			ID:     testhelper.MkID("missing file"),
+			ExpErr: testhelper.MkExpErr("cannot open the image file:"),
+			v:      fName + ".nonesuch:3",
+		},
+		{
+			ID: testhelper.MkID("not an image"),
+			ExpErr: testhelper.MkExpErr(
+				"the format is not supported (use PNG, JPEG or GIF)"),
+			v: "imagePalette_test.go:3",
+		},
+		{
+			ID: testhelper.MkID("hard to tell apart"),
+			ExpErr: testhelper.MkExpErr(`the colours "#800000" and "#820200"` +
+				" are hard to tell apart with protanopia"),
+			s: ImagePalette{CVDMinDeltaE: 10},
+			v: fName,
+		},
+		{
+			ID:     testhelper.MkID("easy to tell apart"),
+			s:      ImagePalette{CVDMinDeltaE: 10},
+			v:      fName + ":3",
+			expVal: "#4d0033, #820200, #f0f0f0",
+		},
+	}
+
+	for _, tc := range testCases {
+		var ncs []colour.NamedColour
+
+		s := tc.s
+		s.Value = &ncs
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image"
+	"image/color" //nolint:misspell
+	"strconv"
+	"strings"
+)
+
+const imageSamplePrefix = "image:"
+
+// parseSampleCoords parses the coordinates following the "@" in an image
+// sample. There must be either two values (x,y) giving a single pixel or
+// four (x,y,w,h) giving a region.
+func parseSampleCoords(s string) (image.Rectangle, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 && len(parts) != 4 { //nolint:mnd
+		return image.Rectangle{},
+			fmt.Errorf("bad image coordinates %q:"+
+				" x,y or x,y,w,h expected", s)
+	}
+
+	names := []string{"x", "y", "width", "height"}
+	vals := []int{0, 0, 1, 1}
+
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return image.Rectangle{},
+				fmt.Errorf("bad image coordinates %q:"+
+					" the %s (%q) is not a whole number",
+					s, names[i], strings.TrimSpace(p))
+		}
+
+		if v < 0 {
+			return image.Rectangle{},
+				fmt.Errorf("bad image coordinates %q:"+
+					" the %s (%d) must not be negative",
+					s, names[i], v)
+		}
+
+		if v == 0 && i >= 2 { //nolint:mnd
+			return image.Rectangle{},
+				fmt.Errorf("bad image coordinates %q:"+
+					" the %s must be greater than 0",
+					s, names[i])
+		}
+
+		vals[i] = v
+	}
+
+	return image.Rect(vals[0], vals[1], vals[0]+vals[2], vals[1]+vals[3]), nil
+}
+
+// averageColour returns the mean colour of the pixels in the region of
+// the image. Each channel, including the alpha channel, is averaged
+// separately.
+func averageColour(img image.Image, r image.Rectangle) color.RGBA { //nolint:misspell
+	var sum [4]int
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := pixelRGBA(img, x, y)
+			sum[0] += int(c.R)
+			sum[1] += int(c.G)
+			sum[2] += int(c.B)
+			sum[3] += int(c.A)
+		}
+	}
+
+	n := float64(r.Dx() * r.Dy())
+
+	return color.RGBA{ //nolint:misspell
+		R: clampUint8(float64(sum[0]) / n),
+		G: clampUint8(float64(sum[1]) / n),
+		B: clampUint8(float64(sum[2]) / n),
+		A: clampUint8(float64(sum[3]) / n),
+	}
+}
+
+// parseImageSample parses the part of an image sample following the
+// prefix. This is the name of an image file followed by an "@" and either
+// the coordinates of a pixel (x,y) or of a region (x,y,w,h). The colour of
+// the pixel or the average colour of the region is returned. The
+// coordinates are relative to the top-left corner of the image.
+func parseImageSample(s string) (color.RGBA, error) { //nolint:misspell
+	fName, coords, found := cutLast(s, "@")
+	if !found {
+		return color.RGBA{}, //nolint:misspell
+			fmt.Errorf("bad image sample %q:"+
+				" the file name must be followed by @x,y or @x,y,w,h",
+				imageSamplePrefix+s)
+	}
+
+	r, err := parseSampleCoords(coords)
+	if err != nil {
+		return color.RGBA{}, err //nolint:misspell
+	}
+
+	img, err := loadImage(strings.TrimSpace(fName))
+	if err != nil {
+		return color.RGBA{}, err //nolint:misspell
+	}
+
+	b := img.Bounds()
+	r = r.Add(b.Min)
+
+	if !r.In(b) {
+		return color.RGBA{}, //nolint:misspell
+			fmt.Errorf("the image coordinates (%s) are out of bounds:"+
+				" the image is %d x %d pixels",
+				coords, b.Dx(), b.Dy())
+	}
+
+	return averageColour(img, r), nil
+}
@@ -0,0 +1,76 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colourtesthelper"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestImageSample(t *testing.T) {
+	fName := mkTestImage(t)
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v      string
+		expVal color.RGBA //nolint:misspell
+	}{
+		{
+			ID:     testhelper.MkID("pixel"),
+			v:      "image:" + fName + "@1,6",
+			expVal: color.RGBA{R: 0x82, G: 0x02, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("region"),
+			v:      "IMAGE:" + fName + "@0,10,4,4",
+			expVal: color.RGBA{R: 0x78, G: 0x78, B: 0xb8, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("transparent pixel"),
+			v:      "image:" + fName + "@3,14",
+			expVal: color.RGBA{R: 0x12, G: 0x34, B: 0x56}, //nolint:misspell
+		},
+		{
+			ID: testhelper.MkID("out of bounds"),
+			ExpErr: testhelper.MkExpErr(
+				"the image coordinates (3,14,1,2) are out of bounds:" +
+					" the image is 4 x 15 pixels"),
+			v: "image:" + fName + "@3,14,1,2",
+		},
+		{
+			ID: testhelper.MkID("missing coordinates"),
+			ExpErr: testhelper.MkExpErr(
+				"the file name must be followed by @x,y or @x,y,w,h"),
+			v: "image:" + fName,
+		},
+		{
+			ID: testhelper.MkID("bad coordinates"),
+			ExpErr: testhelper.MkExpErr(`bad image coordinates "1,x":` +
+				` the y ("x") is not a whole number`),
+			v: "image:" + fName + "@1,x",
+		},
+		{
+			ID: testhelper.MkID("zero width"),
+			ExpErr: testhelper.MkExpErr(`bad image coordinates "1,1,0,1":` +
+				` the width must be greater than 0`),
+			v: "image:" + fName + "@1,1,0,1",
+		},
+		{
+			ID: testhelper.MkID("unsupported format"),
+			ExpErr: testhelper.MkExpErr(
+				"the format is not supported (use PNG, JPEG or GIF)"),
+			v: "image:imageSample_test.go@1,1",
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := colourParser{}.parseRGBA(tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			colourtesthelper.DiffRGBA(t, tc.IDStr(), "colour", c, tc.expVal)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"math"
+)
+
+// Lab is a colour in the CIE L*a*b* colour space using the D65 white
+// point. L is the lightness in the range 0 to 100; a and b are the
+// green-red and blue-yellow axes.
+type Lab struct {
+	L, A, B float64
+}
+
+// D65 reference white point
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// labF is the non-linear function used in the conversion from XYZ to Lab
+func labF(t float64) float64 {
+	const (
+		delta  = 6.0 / 29.0
+		delta3 = delta * delta * delta
+	)
+
+	if t > delta3 {
+		return math.Cbrt(t)
+	}
+
+	return t/(3*delta*delta) + 4.0/29.0 //nolint:mnd
+}
+
+// linearRGB returns the linear (sRGB-decoded) red, green and blue values of
+// the colour, each in the range 0.0 to 1.0. The alpha channel is ignored.
+func linearRGB(c color.RGBA) (float64, float64, float64) { //nolint:misspell
+	return srgbToLinear(float64(c.R) / math.MaxUint8),
+		srgbToLinear(float64(c.G) / math.MaxUint8),
+		srgbToLinear(float64(c.B) / math.MaxUint8)
+}
+
+// MakeLab converts the sRGB colour into the CIE L*a*b* colour space. The
+// alpha channel is ignored.
+func MakeLab(c color.RGBA) Lab { //nolint:misspell
+	r, g, b := linearRGB(c)
+
+	x := 0.4124564*r + 0.3575761*g + 0.1804375*b //nolint:mnd
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b //nolint:mnd
+	z := 0.0193339*r + 0.1191920*g + 0.9503041*b //nolint:mnd
+
+	fx := labF(x / whiteX)
+	fy := labF(y / whiteY)
+	fz := labF(z / whiteZ)
+
+	return Lab{
+		L: 116*fy - 16,     //nolint:mnd
+		A: 500 * (fx - fy), //nolint:mnd
+		B: 200 * (fy - fz), //nolint:mnd
+	}
+}
@@ -0,0 +1,32 @@
+package coloursetter
+
+import "strings"
+
+// splitColourList splits the string into a list of colours at each
+// occurrence of the separator. Separators within brackets ("{...}" or
+// "(...)") are ignored so that colours such as "RGB{R: 1, G: 2}" or
+// "rgbf(1, 0.5, 0)" are not split.
+func splitColourList(s, sep string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{', '(':
+			depth++
+		case '}', ')':
+			if depth > 0 {
+				depth--
+			}
+		}
+
+		if depth == 0 && strings.HasPrefix(s[i:], sep) {
+			parts = append(parts, s[start:i])
+			i += len(sep) - 1
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
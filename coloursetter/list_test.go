@@ -0,0 +1,40 @@
+package coloursetter
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSplitColourList(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		s      string
+		sep    string
+		expVal []string
+	}{
+		{
+			ID:     testhelper.MkID("simple"),
+			s:      "red,green,blue",
+			sep:    ",",
+			expVal: []string{"red", "green", "blue"},
+		},
+		{
+			ID:     testhelper.MkID("brackets"),
+			s:      "RGB{R: 1, G: 2},rgbf(1, 0.5, 0),blue",
+			sep:    ",",
+			expVal: []string{"RGB{R: 1, G: 2}", "rgbf(1, 0.5, 0)", "blue"},
+		},
+		{
+			ID:     testhelper.MkID("long separator"),
+			s:      "red::green::",
+			sep:    "::",
+			expVal: []string{"red", "green", ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffStringSlice(t, tc.IDStr(), "list",
+			splitColourList(tc.s, tc.sep), tc.expVal)
+	}
+}
@@ -17,14 +17,66 @@ type NamedColour struct {
 
 	Value    *colour.NamedColour
 	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// ShowHex, if set, makes CurrentValue show the colour as a hash
+	// followed by hexadecimal digits in the ChannelOrder.
+	ShowHex bool
+	// Scope, if set, supplies the families used in place of the Families
+	// and defers the interpretation of colour names until all the
+	// parameters have been parsed (see FamilyScope).
+	Scope *FamilyScope
+	// Refs, if set, allows the value to be given as a reference to another
+	// colour parameter registered with the same ColourRefs (see
+	// ColourRefs). The parameter is registered with the ColourRefs when it
+	// is added to the param set.
+	Refs *ColourRefs
+	// CVD, if set, has the Value transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+}
+
+// parser returns the colourParser for this setter
+func (s NamedColour) parser() colourParser {
+	return colourParser{
+		families:     s.Scope.families(s.Families),
+		channelOrder: s.ChannelOrder,
+	}
 }
 
 // SetWithVal (called with the value following the parameter) either parses
 // the NamedColour value or else looks up the supplied colour name. The
 // search is performed "case-blind" - all names are mapped to their
 // lower-case equivalents.
-func (s NamedColour) SetWithVal(_ string, paramVal string) error {
-	nc, err := colour.ParseNamedColour(s.Families, paramVal)
+//
+// If the Scope is set the value is interpreted again, using the final
+// setting of the Scope's families, once all the parameters have been
+// parsed and any error is reported then.
+//
+// If the Refs is set and the value is a reference to another colour
+// parameter the Value is set once all the parameters have been parsed.
+func (s NamedColour) SetWithVal(paramName string, paramVal string) error {
+	if isRef, err := s.Refs.setRef(paramName, paramVal); isRef {
+		return err
+	}
+
+	if s.Scope != nil {
+		s.Scope.record(paramName,
+			func() error { return s.set(paramVal) })
+
+		return nil
+	}
+
+	return s.set(paramVal)
+}
+
+// set parses the value and, if it is valid, sets the Value
+func (s NamedColour) set(paramVal string) error {
+	nc, err := s.parser().parse(paramVal)
 	if err == nil {
 		*s.Value = nc
 	}
@@ -34,7 +86,11 @@ func (s NamedColour) SetWithVal(_ string, paramVal string) error {
 
 // AllowedValues returns a string describing the allowed values
 func (s NamedColour) AllowedValues() string {
-	return colour.NamedColourAllowedValues(s.Families)
+	if s.Refs != nil {
+		return s.parser().allowedValues() + refAllowedValues()
+	}
+
+	return s.parser().allowedValues()
 }
 
 // ValDescribe returns a string describing the value that can follow the
@@ -43,15 +99,23 @@ func (s NamedColour) ValDescribe() string {
 	return "colour"
 }
 
-// CurrentValue returns the current setting of the parameter value
+// CurrentValue returns the current setting of the parameter value. If the
+// value was given as a reference to another colour parameter the name is
+// the reference.
 func (s NamedColour) CurrentValue() string {
+	if s.ShowHex {
+		return s.Value.Name() + " " + s.ChannelOrder.Hex(s.Value.Colour())
+	}
+
 	return s.Value.Name() + fmt.Sprintf("%#4.2v", s.Value.Colour())
 }
 
 // CheckSetter panics if the setter has not been properly created - if the
-// Value is nil or the Families value is incorrect. Possible problems with
-// the Families member include duplicate Families in the set or an invalid
-// Family constant being used.
+// Value is nil or the Families value is incorrect or the ChannelOrder is
+// invalid or the Scope has a nil Value. Possible problems with the Families
+// member include duplicate Families in the set or an invalid Family constant
+// being used. If the CVD is set the Value is registered with it. If the Refs
+// is set the Value is registered with it under the name.
 func (s NamedColour) CheckSetter(name string) {
 	intro := name + ": coloursetter.NamedColour Check failed:"
 
@@ -62,4 +126,17 @@ func (s NamedColour) CheckSetter(name string) {
 	if err := s.Families.Check(); err != nil {
 		panic(intro + " NamedColour.Families: " + err.Error())
 	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " NamedColour.ChannelOrder: " + err.Error())
+	}
+
+	if err := s.Scope.check(); err != nil {
+		panic(intro + " NamedColour.Scope: " + err.Error())
+	}
+
+	s.CVD.register(s.Value, func(t CVDType) {
+		*s.Value = simulateCVDNamed(*s.Value, t)
+	})
+	s.Refs.registerNamedColour(name, s.Value)
 }
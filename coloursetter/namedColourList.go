@@ -0,0 +1,134 @@
+package coloursetter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// NamedColourList is used to set a list of colour values and to also record
+// the name each was given to generate the colour. Each entry in the list is
+// a colour as accepted by the NamedColour setter. If the Families value is
+// not set then the StandardColours families are used.
+//
+//nolint:misspell
+type NamedColourList struct {
+	psetter.ValueReqMandatory
+
+	Value    *[]colour.NamedColour
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// ShowHex, if set, makes CurrentValue show the colours as a hash
+	// followed by hexadecimal digits in the ChannelOrder.
+	ShowHex bool
+	// CVDMinDeltaE, if greater than zero, causes the list to be rejected if
+	// any two of its colours, as seen by someone with protanopia,
+	// deuteranopia or tritanopia, differ by less than this (CIEDE2000)
+	// Delta E.
+	CVDMinDeltaE float64
+	// CVD, if set, has the colours transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+	// The StrListSeparator allows you to override the default separator
+	// between list elements.
+	psetter.StrListSeparator
+}
+
+// parser returns the colourParser for this setter
+func (s NamedColourList) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// list of colours and, if they are all valid, sets the Value.
+func (s NamedColourList) SetWithVal(_ string, paramVal string) error {
+	ncs := []colour.NamedColour{}
+
+	for i, entry := range splitColourList(paramVal, s.GetSeparator()) {
+		nc, err := s.parser().parse(entry)
+		if err != nil {
+			return fmt.Errorf("bad colour %d (%q): %w", i+1, entry, err)
+		}
+
+		ncs = append(ncs, nc)
+	}
+
+	if s.CVDMinDeltaE > 0 {
+		if err := checkCVDDistinct(namedCVDColours(ncs),
+			s.CVDMinDeltaE); err != nil {
+			return err
+		}
+	}
+
+	*s.Value = ncs
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s NamedColourList) AllowedValues() string {
+	return s.ListValDesc("colours") +
+		cvdAllowedValues("the colours", s.CVDMinDeltaE) +
+		"\n\n" +
+		"A colour is given as follows. " + s.parser().allowedValues()
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s NamedColourList) ValDescribe() string {
+	return "colours"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s NamedColourList) CurrentValue() string {
+	vals := make([]string, 0, len(*s.Value))
+
+	for _, nc := range *s.Value {
+		if s.ShowHex {
+			vals = append(vals,
+				nc.Name()+" "+s.ChannelOrder.Hex(nc.Colour()))
+		} else {
+			vals = append(vals,
+				nc.Name()+fmt.Sprintf("%#4.2v", nc.Colour()))
+		}
+	}
+
+	return strings.Join(vals, s.GetSeparator())
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder is
+// invalid or the CVDMinDeltaE is negative. If the CVD is set the Value is
+// registered with it.
+func (s NamedColourList) CheckSetter(name string) {
+	intro := name + ": coloursetter.NamedColourList Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " NamedColourList.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " NamedColourList.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " NamedColourList.ChannelOrder: " + err.Error())
+	}
+
+	if s.CVDMinDeltaE < 0 {
+		panic(fmt.Sprintf("%s NamedColourList.CVDMinDeltaE: %g is negative",
+			intro, s.CVDMinDeltaE))
+	}
+
+	s.CVD.registerNamedColours(s.Value)
+}
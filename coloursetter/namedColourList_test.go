@@ -0,0 +1,64 @@
+package coloursetter
+
+import (
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestNamedColourListSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      NamedColourList
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("names"),
+			s:      NamedColourList{ShowHex: true},
+			v:      "red,Navy",
+			expVal: "red #ff0000ff,Navy #000080ff",
+		},
+		{
+			ID: testhelper.MkID("families, with a separator"),
+			s: NamedColourList{
+				Families:         colour.Families{colour.X11Colours},
+				ShowHex:          true,
+				StrListSeparator: psetter.StrListSeparator{Sep: ";"},
+			},
+			v:      "gray;RGB{R: 1, G: 2, B: 3}",
+			expVal: "gray #bebebeff;RGB{R: 1, G: 2, B: 3} #010203ff",
+		},
+		{
+			ID: testhelper.MkID("bad colour"),
+			ExpErr: testhelper.MkExpErr(
+				`bad colour 2 ("nonesuch"):`,
+				`"nonesuch"`),
+			v: "red,nonesuch",
+		},
+		{
+			ID: testhelper.MkID("not distinct for CVD"),
+			ExpErr: testhelper.MkExpErr(
+				`"red" and "green"`),
+			s: NamedColourList{CVDMinDeltaE: 20},
+			v: "red,green",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v []colour.NamedColour
+
+		s := tc.s
+		s.Value = &v
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
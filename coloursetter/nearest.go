@@ -0,0 +1,322 @@
+package coloursetter
+
+import (
+	"cmp"
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+)
+
+// Nearest records the result of a nearest-colour search
+type Nearest struct {
+	// Name is the family-qualified name of the nearest colour
+	Name string
+	// Colour is the nearest colour
+	Colour color.RGBA //nolint:misspell
+	// DeltaE is the distance from the target colour to the nearest colour
+	DeltaE float64
+}
+
+// String returns a description of the nearest colour suitable for display
+// following the target colour
+func (n Nearest) String() string {
+	if n.DeltaE == 0 {
+		return "= " + n.Name
+	}
+
+	return fmt.Sprintf("≈ %s, ΔE %.1f", n.Name, n.DeltaE)
+}
+
+// labEntry is a named colour from the Families together with its
+// L*a*b* value
+type labEntry struct {
+	lab    Lab
+	name   string
+	colour color.RGBA //nolint:misspell
+}
+
+// labCoord returns the i'th coordinate of the Lab value
+func labCoord(l Lab, i int) float64 {
+	switch i {
+	case 0:
+		return l.L
+	case 1:
+		return l.A
+	}
+
+	return l.B
+}
+
+// kdNode is a node in a k-d tree of Lab values
+type kdNode struct {
+	entry       labEntry
+	axis        int
+	left, right *kdNode
+}
+
+// buildKDTree constructs a balanced k-d tree from the entries. Note that
+// the entries are re-ordered.
+func buildKDTree(entries []labEntry, depth int) *kdNode {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	const dimensions = 3
+
+	axis := depth % dimensions
+
+	slices.SortFunc(entries, func(a, b labEntry) int {
+		return cmp.Compare(labCoord(a.lab, axis), labCoord(b.lab, axis))
+	})
+
+	mid := len(entries) / 2 //nolint:mnd
+
+	return &kdNode{
+		entry: entries[mid],
+		axis:  axis,
+		left:  buildKDTree(entries[:mid], depth+1),
+		right: buildKDTree(entries[mid+1:], depth+1),
+	}
+}
+
+// search calls visit for the entries in the tree which may lie within the
+// radius (the Euclidean distance) of the target, nearer entries tending to
+// be visited first. The radius is found afresh before each subtree is
+// searched so that it can shrink as closer entries are found.
+func (n *kdNode) search(target Lab, radius func() float64,
+	visit func(labEntry),
+) {
+	if n == nil {
+		return
+	}
+
+	visit(n.entry)
+
+	diff := labCoord(target, n.axis) - labCoord(n.entry.lab, n.axis)
+
+	near, far := n.left, n.right
+	if diff > 0 {
+		near, far = far, near
+	}
+
+	near.search(target, radius, visit)
+
+	if math.Abs(diff) <= radius() {
+		far.search(target, radius, visit)
+	}
+}
+
+// labIndex is a spatial index over the colours in a collection of Families
+type labIndex struct {
+	root *kdNode
+}
+
+// labIndexes caches the indexes, keyed by the Families they were
+// constructed from. Building an index for a large Family is expensive and
+// so it is only done once.
+var (
+	labIndexes    = map[string]*labIndex{}
+	labIndexMutex sync.Mutex
+)
+
+// preferredColourName returns the preferred name from the list. We prefer
+// shorter names over longer and names coming earlier in lexical order over
+// names coming later.
+func preferredColourName(names []string) string {
+	return slices.MinFunc(names, func(a, b string) int {
+		if d := len(a) - len(b); d != 0 {
+			return d
+		}
+
+		return strings.Compare(a, b)
+	})
+}
+
+// makeLabIndex builds the spatial index of the colours in the Families
+func makeLabIndex(fl colour.Families) (*labIndex, error) {
+	all, err := fl.ClosestWithin(color.RGBA{}, colour.MaxColourProximity) //nolint:misspell
+	if err != nil {
+		return nil, err
+	}
+
+	type familyRGBA struct {
+		f colour.Family
+		c color.RGBA //nolint:misspell
+	}
+
+	names := map[familyRGBA][]string{}
+	keys := []familyRGBA{}
+
+	for _, fc := range all {
+		k := familyRGBA{f: fc.Family, c: fc.Colour}
+		if _, ok := names[k]; !ok {
+			keys = append(keys, k)
+		}
+
+		names[k] = append(names[k], fc.CNames...)
+	}
+
+	entries := make([]labEntry, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries,
+			labEntry{
+				lab:    MakeLab(k.c),
+				name:   k.f.Name() + ":" + preferredColourName(names[k]),
+				colour: k.c,
+			})
+	}
+
+	// sort the entries so that the tree does not depend on the order of
+	// the colours
+	slices.SortFunc(entries, func(a, b labEntry) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return &labIndex{root: buildKDTree(entries, 0)}, nil
+}
+
+// getLabIndex returns the (possibly cached) index for the Families
+func getLabIndex(fl colour.Families) (*labIndex, error) {
+	key := fl.String()
+
+	labIndexMutex.Lock()
+	defer labIndexMutex.Unlock()
+
+	if idx, ok := labIndexes[key]; ok {
+		return idx, nil
+	}
+
+	idx, err := makeLabIndex(fl)
+	if err != nil {
+		return nil, err
+	}
+
+	labIndexes[key] = idx
+
+	return idx, nil
+}
+
+// These are used to bound the Euclidean (CIE76) distance between two
+// colours in terms of their CIEDE2000 difference (see labRadius)
+const (
+	// de2000MaxSL is the largest value of the CIEDE2000 lightness weight,
+	// SL, for lightnesses in the range 0 to 100
+	de2000MaxSL = 1.7471
+	// de2000MinRTWeight is the smallest value of 1 - |RT|/2 where RT is
+	// the CIEDE2000 rotation term: |RT| < 2.sin(60°) so this is
+	// 1 - sin(60°)
+	de2000MinRTWeight = 0.1339
+	// de2000MaxChromaScale is the largest ratio of the CIEDE2000 adjusted
+	// chroma, C', to the chroma, C (it is 1 + G and G is at most 0.5)
+	de2000MaxChromaScale = 1.5
+	// de2000KC is the coefficient of the mean adjusted chroma in the
+	// CIEDE2000 chroma weight, SC
+	de2000KC = 0.045
+	// radiusSlack allows for rounding errors in the bounds
+	radiusSlack = 1e-9
+)
+
+// labRadius returns a Euclidean distance in L*a*b* space such that any
+// colour whose difference from the target, measured by the formula, is no
+// more than d lies within that distance of the target. The result may be
+// infinite, in which case no colour can be ruled out.
+//
+// For CIE76 the distance is d itself. For CIE94 (with the target as the
+// reference) each of the lightness, chroma and hue differences is divided
+// by a weight of at most SC = 1 + K1.C, where C is the chroma of the
+// target, so the distance is at most SC.d.
+//
+// For CIEDE2000 the differences of lightness, adjusted chroma and hue
+// (ΔL', ΔC' and ΔH') satisfy ΔL'² + ΔC'² + ΔH'² = ΔL² + Δa'² + Δb², which is
+// no less than the squared Euclidean distance, E², as a' = (1 + G).a with
+// G ≥ 0 the same for both colours. Each difference is divided by a weight
+// of at most S = max(SL, SC) (SH is always less than SC) and the rotation
+// term can reduce the sum of the squared chroma and hue terms by at most a
+// factor of w = 1 - sin(60°). So E ≤ S.d/√w. The mean adjusted chroma is
+// at most 1.5 times the mean chroma, which is at most C + E/2, and so SC
+// is at most 1 + 0.045.1.5.(C + E/2); solving for E gives the bound.
+func (f DeltaEFormula) labRadius(target Lab, d float64) float64 {
+	c := math.Hypot(target.A, target.B)
+
+	var r float64
+
+	switch f {
+	case CIE76:
+		r = d
+	case CIE94:
+		r = (1 + de94K1*c) * d
+	default:
+		scale := d / math.Sqrt(de2000MinRTWeight)
+		k := de2000KC * de2000MaxChromaScale
+
+		denom := 1 - k*scale/2 //nolint:mnd
+		if denom <= 0 {
+			return math.Inf(1)
+		}
+
+		r = max(de2000MaxSL*scale, (1+k*c)*scale/denom)
+	}
+
+	return r * (1 + radiusSlack)
+}
+
+// FindNearest returns the colour from the Families that is perceptually
+// closest to the target colour, as measured by the given formula. If no
+// families are given then the standard families are used. The alpha channel
+// is ignored. A spatial index of the colours in the Families is built on
+// first use and then reused so that searching even a large Family is fast.
+// The index is searched for the colours whose Euclidean (CIE76) distance
+// from the target is small enough that they might be the nearest by the
+// given formula and only these are compared using the formula. If several
+// colours are equally near the one with the lowest name is chosen.
+func FindNearest(
+	fl colour.Families, target color.RGBA, f DeltaEFormula, //nolint:misspell
+) (Nearest, error) {
+	if err := f.Check(); err != nil {
+		return Nearest{}, err
+	}
+
+	idx, err := getLabIndex(fl)
+	if err != nil {
+		return Nearest{}, err
+	}
+
+	if idx.root == nil {
+		return Nearest{}, fmt.Errorf("there are no colours in %s", fl)
+	}
+
+	tLab := MakeLab(target)
+
+	best := Nearest{DeltaE: math.Inf(1)}
+	radius := math.Inf(1)
+
+	idx.root.search(tLab,
+		func() float64 { return radius },
+		func(cand labEntry) {
+			if deltaE76(tLab, cand.lab) > radius {
+				return
+			}
+
+			dist := f.labDist(tLab, cand.lab)
+			if cand.lab == tLab {
+				dist = 0
+			}
+
+			if dist < best.DeltaE ||
+				(dist == best.DeltaE && cand.name < best.Name) {
+				best = Nearest{
+					Name:   cand.name,
+					Colour: cand.colour,
+					DeltaE: dist,
+				}
+				radius = f.labRadius(tLab, dist)
+			}
+		})
+
+	return best, nil
+}
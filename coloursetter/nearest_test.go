@@ -0,0 +1,188 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestMakeLab(t *testing.T) {
+	const epsilon = 0.01
+
+	testCases := []struct {
+		testhelper.ID
+		c      color.RGBA //nolint:misspell
+		expVal Lab
+	}{
+		{
+			ID:     testhelper.MkID("white"),
+			c:      color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, //nolint:misspell
+			expVal: Lab{L: 100},
+		},
+		{
+			ID:     testhelper.MkID("black"),
+			c:      color.RGBA{A: 0xff}, //nolint:misspell
+			expVal: Lab{},
+		},
+		{
+			ID:     testhelper.MkID("red"),
+			c:      color.RGBA{R: 0xff, A: 0xff}, //nolint:misspell
+			expVal: Lab{L: 53.24, A: 80.09, B: 67.20},
+		},
+	}
+
+	for _, tc := range testCases {
+		lab := MakeLab(tc.c)
+		if math.Abs(lab.L-tc.expVal.L) > epsilon ||
+			math.Abs(lab.A-tc.expVal.A) > epsilon ||
+			math.Abs(lab.B-tc.expVal.B) > epsilon {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected: %v", tc.expVal)
+			t.Logf("\t:      got: %v", lab)
+			t.Error("\t: unexpected Lab value")
+		}
+	}
+}
+
+func TestFindNearest(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		fl      colour.Families
+		f       DeltaEFormula
+		c       color.RGBA //nolint:misspell
+		expName string
+		expDist string
+	}{
+		{
+			ID:      testhelper.MkID("exact"),
+			fl:      colour.Families{colour.X11Colours},
+			c:       color.RGBA{B: 0x80, A: 0xff}, //nolint:misspell
+			expName: "x11:navy",
+			expDist: "0.0",
+		},
+		{
+			ID:      testhelper.MkID("standard families"),
+			c:       color.RGBA{R: 0x1f, G: 0x3b, B: 0x70, A: 0xff}, //nolint:misspell
+			expName: "pantone:mazarine blue",
+			expDist: "1.7",
+		},
+		{
+			ID:      testhelper.MkID("large family"),
+			fl:      colour.Families{colour.EncycolorpediaColours},
+			c:       color.RGBA{R: 0x1f, G: 0x3b, B: 0x70, A: 0xff}, //nolint:misspell
+			expName: "encycolorpedia:tardis blue",
+			expDist: "3.4",
+		},
+		{
+			ID:      testhelper.MkID("CIE94"),
+			fl:      colour.Families{colour.X11Colours},
+			f:       CIE94,
+			c:       color.RGBA{R: 0x1f, G: 0x3b, B: 0x70, A: 0xff}, //nolint:misspell
+			expName: "x11:royalblue4",
+			expDist: "7.0",
+		},
+		{
+			ID:     testhelper.MkID("bad formula"),
+			ExpErr: testhelper.MkExpErr("-1 is not a valid DeltaEFormula"),
+			f:      DeltaEFormula(-1),
+		},
+		{
+			ID:     testhelper.MkID("bad family"),
+			ExpErr: testhelper.MkExpErr(`"nonesuch" is not a valid Family`),
+			fl:     colour.Families{"nonesuch"},
+		},
+	}
+
+	for _, tc := range testCases {
+		n, err := FindNearest(tc.fl, tc.c, tc.f)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "name", n.Name, tc.expName)
+			testhelper.DiffString(t, tc.IDStr(), "Delta E",
+				strconv.FormatFloat(n.DeltaE, 'f', 1, 64), tc.expDist)
+		}
+	}
+}
+
+// TestFindNearestIndex checks that FindNearest finds the same colour as a
+// linear scan of all the colours, for each of the formulae
+func TestFindNearestIndex(t *testing.T) {
+	fl := colour.Families{colour.EncycolorpediaColours}
+
+	all, err := fl.AllColours()
+	if err != nil {
+		t.Fatal("cannot get the colours: ", err)
+	}
+
+	allLab := make([]Lab, 0, len(all))
+	for _, fc := range all {
+		allLab = append(allLab, MakeLab(fc))
+	}
+
+	r := rand.New(rand.NewPCG(1, 2)) //nolint:gosec
+
+	for range 100 {
+		c := color.RGBA{ //nolint:misspell
+			R: uint8(r.UintN(256)), //nolint:gosec
+			G: uint8(r.UintN(256)), //nolint:gosec
+			B: uint8(r.UintN(256)), //nolint:gosec
+			A: math.MaxUint8,
+		}
+		cLab := MakeLab(c)
+
+		for _, f := range []DeltaEFormula{CIE76, CIE94, CIEDE2000} {
+			minDist := math.MaxFloat64
+			for _, l := range allLab {
+				minDist = min(minDist, f.labDist(cLab, l))
+			}
+
+			n, err := FindNearest(fl, c, f)
+			if err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+
+			if math.Abs(n.DeltaE-minDist) > 1e-9 {
+				t.Logf("colour: %s, formula: %s", ChannelOrderRGB.Hex(c), f)
+				t.Logf("\t: expected: %f", minDist)
+				t.Logf("\t:      got: %f (%s)", n.DeltaE, n.Name)
+				t.Error("\t: the nearest colour was not found")
+			}
+		}
+	}
+}
+
+// TestLabRadius checks that, for each of the formulae, a colour is never
+// further (in Euclidean distance) from the target than the radius given for
+// its difference from the target
+func TestLabRadius(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4)) //nolint:gosec
+
+	randomLab := func() Lab {
+		return MakeLab(color.RGBA{ //nolint:misspell
+			R: uint8(r.UintN(256)), //nolint:gosec
+			G: uint8(r.UintN(256)), //nolint:gosec
+			B: uint8(r.UintN(256)), //nolint:gosec
+			A: math.MaxUint8,
+		})
+	}
+
+	for range 10000 {
+		target, sample := randomLab(), randomLab()
+		dist := deltaE76(target, sample)
+
+		for _, f := range []DeltaEFormula{CIE76, CIE94, CIEDE2000} {
+			radius := f.labRadius(target, f.labDist(target, sample))
+			if dist > radius {
+				t.Logf("target: %v, sample: %v, formula: %s", target, sample, f)
+				t.Logf("\t: distance: %f", dist)
+				t.Logf("\t:   radius: %f", radius)
+				t.Error("\t: the colour lies outside the radius")
+			}
+		}
+	}
+}
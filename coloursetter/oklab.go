@@ -0,0 +1,127 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"math"
+)
+
+// OKLab is a colour in the OKLab colour space (Björn Ottosson, 2020). L is
+// the perceived lightness in the range 0.0 to 1.0; A and B are the
+// green-red and blue-yellow axes, roughly in the range -0.4 to 0.4.
+// Euclidean distances in this space closely match perceived differences.
+type OKLab struct {
+	L, A, B float64
+}
+
+// gamutEpsilon is the tolerance allowed when checking that a linear RGB
+// channel value lies within the sRGB gamut
+const gamutEpsilon = 1e-6
+
+// MakeOKLab converts the sRGB colour into the OKLab colour space. The alpha
+// channel is ignored.
+//
+//nolint:mnd
+func MakeOKLab(c color.RGBA) OKLab { //nolint:misspell
+	r, g, b := linearRGB(c)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return OKLab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// linearRGB returns the linear red, green and blue values of the colour.
+// The values may lie outside the range 0.0 to 1.0 if the colour is outside
+// the sRGB gamut.
+//
+//nolint:mnd
+func (o OKLab) linearRGB() (float64, float64, float64) {
+	l := o.L + 0.3963377774*o.A + 0.2158037573*o.B
+	m := o.L - 0.1055613458*o.A - 0.0638541728*o.B
+	s := o.L - 0.0894841775*o.A - 1.2914855480*o.B
+
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return 4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+}
+
+// InGamut returns true if the colour can be represented in the sRGB colour
+// space
+func (o OKLab) InGamut() bool {
+	r, g, b := o.linearRGB()
+
+	for _, v := range []float64{r, g, b} {
+		if v < -gamutEpsilon || v > 1+gamutEpsilon {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ToRGBA converts the colour into an opaque sRGB colour. Colours outside the
+// sRGB gamut are clipped.
+func (o OKLab) ToRGBA() color.RGBA { //nolint:misspell
+	r, g, b := o.linearRGB()
+
+	toSRGB := func(v float64) uint8 {
+		return clampUint8(linearToSRGB(max(0, min(1, v))) * math.MaxUint8)
+	}
+
+	return color.RGBA{R: toSRGB(r), G: toSRGB(g), B: toSRGB(b), A: math.MaxUint8} //nolint:misspell
+}
+
+// Chroma returns the chroma (colourfulness) of the colour
+func (o OKLab) Chroma() float64 {
+	return math.Hypot(o.A, o.B)
+}
+
+// Dist returns the Euclidean distance between the two colours
+func (o OKLab) Dist(other OKLab) float64 {
+	dL := o.L - other.L
+	da := o.A - other.A
+	db := o.B - other.B
+
+	return math.Sqrt(dL*dL + da*da + db*db)
+}
+
+// Hue returns the hue angle of the colour in radians
+func (o OKLab) Hue() float64 {
+	return math.Atan2(o.B, o.A)
+}
+
+// MakeOKLCH returns the OKLab colour having the given lightness, chroma and
+// hue (in radians)
+func MakeOKLCH(l, chroma, hue float64) OKLab {
+	return OKLab{L: l, A: chroma * math.Cos(hue), B: chroma * math.Sin(hue)}
+}
+
+// ToRGBAInGamut converts the colour into an opaque sRGB colour. If the
+// colour is outside the sRGB gamut its chroma is reduced, keeping the
+// lightness and hue, until it is within the gamut.
+func (o OKLab) ToRGBAInGamut() color.RGBA { //nolint:misspell
+	const (
+		gamutSearchSteps = 20
+		gamutSearchScale = 0.9
+	)
+
+	chroma, hue := o.Chroma(), o.Hue()
+
+	for range gamutSearchSteps {
+		ok := MakeOKLCH(o.L, chroma, hue)
+		if ok.InGamut() {
+			return ok.ToRGBA()
+		}
+
+		chroma *= gamutSearchScale
+	}
+
+	return OKLab{L: o.L}.ToRGBA()
+}
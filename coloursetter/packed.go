@@ -0,0 +1,370 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// PackedFormat identifies the layout of a colour packed into a small
+// integer as used by embedded displays
+type PackedFormat string
+
+// These are the supported packed formats. The channels are listed from the
+// most significant bits downwards.
+const (
+	PackedRGB565 PackedFormat = "RGB565"
+	PackedBGR565 PackedFormat = "BGR565"
+	PackedRGB555 PackedFormat = "RGB555"
+	PackedRGB444 PackedFormat = "RGB444"
+	PackedRGB332 PackedFormat = "RGB332"
+)
+
+// packedChannel describes one channel in a packed format
+type packedChannel struct {
+	name  byte
+	bits  uint
+	shift uint
+}
+
+// packedLayouts gives the channel layout of each packed format
+var packedLayouts = map[PackedFormat][]packedChannel{
+	PackedRGB565: {{'R', 5, 11}, {'G', 6, 5}, {'B', 5, 0}},
+	PackedBGR565: {{'B', 5, 11}, {'G', 6, 5}, {'R', 5, 0}},
+	PackedRGB555: {{'R', 5, 10}, {'G', 5, 5}, {'B', 5, 0}},
+	PackedRGB444: {{'R', 4, 8}, {'G', 4, 4}, {'B', 4, 0}},
+	PackedRGB332: {{'R', 3, 5}, {'G', 3, 2}, {'B', 2, 0}},
+}
+
+// format returns the packed format, the zero value is mapped to the default
+// format (RGB565).
+func (pf PackedFormat) format() PackedFormat {
+	if pf == "" {
+		return PackedRGB565
+	}
+
+	return pf
+}
+
+// Check returns a non-nil error if the PackedFormat is not one of the
+// supported values.
+func (pf PackedFormat) Check() error {
+	if _, ok := packedLayouts[pf.format()]; !ok {
+		return fmt.Errorf("%q is not a valid PackedFormat", string(pf))
+	}
+
+	return nil
+}
+
+// bits returns the total number of bits used by the packed format
+func (pf PackedFormat) bits() uint {
+	var total uint
+
+	for _, pc := range packedLayouts[pf.format()] {
+		total += pc.bits
+	}
+
+	return total
+}
+
+// PackedRounding identifies how an 8-bit channel value is reduced to the
+// smaller number of bits available in a packed format. No dithering is
+// performed.
+type PackedRounding int
+
+// These are the available rounding modes
+const (
+	// RoundNearest gives the nearest representable value
+	RoundNearest PackedRounding = iota
+	// RoundDown gives the largest representable value that is not greater
+	// than the original
+	RoundDown
+	// RoundUp gives the smallest representable value that is not less
+	// than the original
+	RoundUp
+)
+
+// Check returns a non-nil error if the PackedRounding is not one of the
+// supported values.
+func (r PackedRounding) Check() error {
+	if r < RoundNearest || r > RoundUp {
+		return fmt.Errorf("%d is not a valid PackedRounding", int(r))
+	}
+
+	return nil
+}
+
+// reduce converts the 8-bit value to a value with the given number of bits
+func (r PackedRounding) reduce(v uint8, bits uint) uint16 {
+	maxVal := float64(uint(1)<<bits - 1)
+	scaled := float64(v) * maxVal / math.MaxUint8
+
+	switch r {
+	case RoundDown:
+		return uint16(math.Floor(scaled))
+	case RoundUp:
+		return uint16(math.Ceil(scaled))
+	default:
+		return uint16(math.Round(scaled))
+	}
+}
+
+// expand converts the value with the given number of bits back to an 8-bit
+// value
+func expand(v uint16, bits uint) uint8 {
+	maxVal := float64(uint(1)<<bits - 1)
+
+	return uint8(math.Round(float64(v) * math.MaxUint8 / maxVal))
+}
+
+// Pack returns the colour packed into the given format using the given
+// rounding. The alpha channel is ignored.
+func Pack(c color.RGBA, pf PackedFormat, r PackedRounding) uint16 { //nolint:misspell
+	var packed uint16
+
+	for _, pc := range packedLayouts[pf.format()] {
+		v := c.R
+
+		switch pc.name {
+		case 'G':
+			v = c.G
+		case 'B':
+			v = c.B
+		}
+
+		packed |= r.reduce(v, pc.bits) << pc.shift
+	}
+
+	return packed
+}
+
+// Unpack returns the colour represented by the packed value in the given
+// format. The colour is fully opaque.
+func Unpack(v uint16, pf PackedFormat) color.RGBA { //nolint:misspell
+	c := color.RGBA{A: math.MaxUint8} //nolint:misspell
+
+	for _, pc := range packedLayouts[pf.format()] {
+		mask := uint16(1)<<pc.bits - 1
+		ch := expand((v>>pc.shift)&mask, pc.bits)
+
+		switch pc.name {
+		case 'R':
+			c.R = ch
+		case 'G':
+			c.G = ch
+		case 'B':
+			c.B = ch
+		}
+	}
+
+	return c
+}
+
+// Packed is used to set a colour packed into a small integer in one of the
+// formats used by embedded displays (see PackedFormat). Any colour value
+// accepted by the RGB setter may be given and the nearest packed value is
+// stored (according to the Rounding). The RGB332 format only uses the low 8
+// bits of the Value; to set a uint8 use the Packed8 setter.
+type Packed struct {
+	psetter.ValueReqMandatory
+
+	Value    *uint16
+	Families colour.Families
+
+	// Format gives the packed format. If it is not set the RGB565 format
+	// is used.
+	Format PackedFormat
+	// Rounding gives the way that the channel values are reduced to fit
+	// in the packed format.
+	Rounding PackedRounding
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+}
+
+// parser returns the colourParser for this setter
+func (s Packed) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// colour and sets the Value to the packed equivalent.
+func (s Packed) SetWithVal(_ string, paramVal string) error {
+	c, err := s.parser().parseRGBA(paramVal)
+	if err != nil {
+		return err
+	}
+
+	*s.Value = Pack(c, s.Format, s.Rounding)
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Packed) AllowedValues() string {
+	return s.parser().allowedValues() +
+		"\n\n" +
+		"The colour is converted to the " + string(s.Format.format()) +
+		" packed format"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Packed) ValDescribe() string {
+	return "colour"
+}
+
+// CurrentValue returns the current setting of the parameter value. This
+// shows both the packed value and the colour that it represents.
+func (s Packed) CurrentValue() string {
+	format := "%#04x (%s: %s)"
+	if s.Format.bits() <= 8 { //nolint:mnd
+		format = "%#02x (%s: %s)"
+	}
+
+	return fmt.Sprintf(format,
+		*s.Value,
+		s.Format.format(),
+		ChannelOrderRGB.Hex(Unpack(*s.Value, s.Format)))
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the Format, Rounding
+// or ChannelOrder is invalid.
+func (s Packed) CheckSetter(name string) {
+	intro := name + ": coloursetter.Packed Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " Packed.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " Packed.Families: " + err.Error())
+	}
+
+	if err := s.Format.Check(); err != nil {
+		panic(intro + " Packed.Format: " + err.Error())
+	}
+
+	if err := s.Rounding.Check(); err != nil {
+		panic(intro + " Packed.Rounding: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " Packed.ChannelOrder: " + err.Error())
+	}
+}
+
+// Packed8 is used to set a colour packed into a single byte. It is the same
+// as the Packed setter except that the Value is a uint8 and so only the
+// formats using no more than 8 bits (RGB332) may be used.
+type Packed8 struct {
+	psetter.ValueReqMandatory
+
+	Value    *uint8
+	Families colour.Families
+
+	// Format gives the packed format. If it is not set the RGB332 format
+	// is used.
+	Format PackedFormat
+	// Rounding gives the way that the channel values are reduced to fit
+	// in the packed format.
+	Rounding PackedRounding
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+}
+
+// format returns the packed format, the zero value is mapped to the default
+// format (RGB332).
+func (s Packed8) format() PackedFormat {
+	if s.Format == "" {
+		return PackedRGB332
+	}
+
+	return s.Format
+}
+
+// parser returns the colourParser for this setter
+func (s Packed8) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// colour and sets the Value to the packed equivalent.
+func (s Packed8) SetWithVal(_ string, paramVal string) error {
+	c, err := s.parser().parseRGBA(paramVal)
+	if err != nil {
+		return err
+	}
+
+	*s.Value = uint8(Pack(c, s.format(), s.Rounding)) //nolint:gosec
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Packed8) AllowedValues() string {
+	return s.parser().allowedValues() +
+		"\n\n" +
+		"The colour is converted to the " + string(s.format()) +
+		" packed format"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Packed8) ValDescribe() string {
+	return "colour"
+}
+
+// CurrentValue returns the current setting of the parameter value. This
+// shows both the packed value and the colour that it represents.
+func (s Packed8) CurrentValue() string {
+	return fmt.Sprintf("%#02x (%s: %s)",
+		*s.Value,
+		s.format(),
+		ChannelOrderRGB.Hex(Unpack(uint16(*s.Value), s.format())))
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the Format, Rounding
+// or ChannelOrder is invalid or the Format needs more than 8 bits.
+func (s Packed8) CheckSetter(name string) {
+	intro := name + ": coloursetter.Packed8 Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " Packed8.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " Packed8.Families: " + err.Error())
+	}
+
+	if err := s.format().Check(); err != nil {
+		panic(intro + " Packed8.Format: " + err.Error())
+	}
+
+	if bits := s.format().bits(); bits > 8 { //nolint:mnd
+		panic(fmt.Sprintf("%s Packed8.Format: %s needs %d bits (at most 8)",
+			intro, s.format(), bits))
+	}
+
+	if err := s.Rounding.Check(); err != nil {
+		panic(intro + " Packed8.Rounding: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " Packed8.ChannelOrder: " + err.Error())
+	}
+}
@@ -0,0 +1,168 @@
+package coloursetter
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestPackedSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      Packed
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("RGB565 - red"),
+			v:      "#ff0000",
+			expVal: "0xf800 (RGB565: #ff0000)",
+		},
+		{
+			ID:     testhelper.MkID("BGR565 - red"),
+			s:      Packed{Format: PackedBGR565},
+			v:      "#ff0000",
+			expVal: "0x001f (BGR565: #ff0000)",
+		},
+		{
+			ID:     testhelper.MkID("RGB555 - grey, nearest"),
+			s:      Packed{Format: PackedRGB555},
+			v:      "#808080",
+			expVal: "0x4210 (RGB555: #848484)",
+		},
+		{
+			ID: testhelper.MkID("RGB555 - grey, down"),
+			s: Packed{
+				Format:   PackedRGB555,
+				Rounding: RoundDown,
+			},
+			v:      "#808080",
+			expVal: "0x3def (RGB555: #7b7b7b)",
+		},
+		{
+			ID: testhelper.MkID("RGB444 - grey, up"),
+			s: Packed{
+				Format:   PackedRGB444,
+				Rounding: RoundUp,
+			},
+			v:      "#898989",
+			expVal: "0x0999 (RGB444: #999999)",
+		},
+		{
+			ID: testhelper.MkID("RGB444 - grey, down"),
+			s: Packed{
+				Format:   PackedRGB444,
+				Rounding: RoundDown,
+			},
+			v:      "#898989",
+			expVal: "0x0888 (RGB444: #888888)",
+		},
+		{
+			ID:     testhelper.MkID("RGB332 - named"),
+			s:      Packed{Format: PackedRGB332},
+			v:      "web:yellow",
+			expVal: "0xfc (RGB332: #ffff00)",
+		},
+		{
+			ID:     testhelper.MkID("bad colour"),
+			ExpErr: testhelper.MkExpErr(`bad colour name: "nonesuch"`),
+			v:      "nonesuch",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v uint16
+
+		s := tc.s
+		s.Value = &v
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestPacked8SetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      Packed8
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("default format - yellow"),
+			v:      "web:yellow",
+			expVal: "0xfc (RGB332: #ffff00)",
+		},
+		{
+			ID:     testhelper.MkID("grey, nearest"),
+			v:      "#808080",
+			expVal: "0x92 (RGB332: #9292aa)",
+		},
+		{
+			ID:     testhelper.MkID("grey, down"),
+			s:      Packed8{Rounding: RoundDown},
+			v:      "#808080",
+			expVal: "0x6d (RGB332: #6d6d55)",
+		},
+		{
+			ID:     testhelper.MkID("bad colour"),
+			ExpErr: testhelper.MkExpErr(`bad colour name: "nonesuch"`),
+			v:      "nonesuch",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v uint8
+
+		s := tc.s
+		s.Value = &v
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestPacked8Check(t *testing.T) {
+	var v uint8
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		s Packed8
+	}{
+		{
+			ID: testhelper.MkID("No panic expected"),
+			s:  Packed8{Value: &v},
+		},
+		{
+			ID: testhelper.MkID("Panic expected, nil Value"),
+			ExpPanic: testhelper.MkExpPanic(
+				"test-param: coloursetter.Packed8 Check failed:" +
+					" Packed8.Value: is nil"),
+		},
+		{
+			ID: testhelper.MkID("Panic expected, 16-bit format"),
+			ExpPanic: testhelper.MkExpPanic(
+				"test-param: coloursetter.Packed8 Check failed:" +
+					" Packed8.Format: RGB565 needs 16 bits (at most 8)"),
+			s: Packed8{Value: &v, Format: PackedRGB565},
+		},
+	}
+
+	for _, tc := range testCases {
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			tc.s.CheckSetter("test-param")
+		})
+		testhelper.CheckExpPanic(t, panicked, panicVal, tc)
+	}
+}
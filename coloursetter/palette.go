@@ -0,0 +1,417 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"image/color/palette"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// DfltPaletteMaxLen is the default maximum number of entries in a palette
+// set by the Palette setter. This is the largest palette that can be used
+// for a GIF or a paletted PNG image.
+const DfltPaletteMaxLen = 256
+
+// These are the names and prefixes of the special palette entries
+const (
+	palettePlan9        = "plan9"
+	paletteWebSafe      = "websafe"
+	paletteFamilyPrefix = "family:"
+	paletteFilePrefix   = "file:"
+
+	paletteFileCommentIntro = "//"
+	paletteMaxShown         = 16
+	paletteMaxDupErrs       = 10
+)
+
+// builtinPalettes maps the names of the standard library palettes to the
+// palettes
+var builtinPalettes = map[string]color.Palette{ //nolint:misspell
+	palettePlan9:   palette.Plan9,
+	paletteWebSafe: palette.WebSafe,
+}
+
+// paletteEntry records a colour in a palette together with the parameter
+// value entry that generated it
+type paletteEntry struct {
+	c      color.RGBA //nolint:misspell
+	source string
+}
+
+// Palette is used to set a standard library color.Palette as used by the GIF
+// and PNG image encoders. The value is a list of entries, each of which is
+// either a colour (as accepted by the RGB setter), the name of one of the
+// standard library palettes (plan9 or websafe), all the colours in a colour
+// family (family:name), the colours listed in a file (file:pathname), a
+// number of generated, maximally distinct colours (distinct:N), a scale of
+// tints and shades of a colour (scale:colour:N) or a colour and its
+// companions in a colour harmony (harmony:scheme(colour)).
+//
+//nolint:misspell
+type Palette struct {
+	psetter.ValueReqMandatory
+
+	Value    *color.Palette
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// MaxLen gives the maximum number of entries allowed in the
+	// palette. If it is not set DfltPaletteMaxLen is used.
+	MaxLen int
+	// AllowDuplicates, if set, causes duplicate colours to be silently
+	// removed from the palette, otherwise they are reported as errors.
+	AllowDuplicates bool
+	// CVDMinDeltaE, if greater than zero, causes the palette to be
+	// rejected if any two of its colours, as seen by someone with
+	// protanopia, deuteranopia or tritanopia, differ by less than this
+	// (CIEDE2000) Delta E.
+	CVDMinDeltaE float64
+	// CVD, if set, has the colours transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+	// The StrListSeparator allows you to override the default separator
+	// between list elements.
+	psetter.StrListSeparator
+}
+
+// parser returns the colourParser for this setter
+func (s Palette) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// maxLen returns the maximum allowed length of the palette
+func (s Palette) maxLen() int {
+	if s.MaxLen == 0 {
+		return DfltPaletteMaxLen
+	}
+
+	return s.MaxLen
+}
+
+// largeFamilies returns a description of each of the colour families
+// having more distinct colours than the given maximum, in name order
+func largeFamilies(maxLen int) []string {
+	large := []string{}
+
+	for _, fName := range slices.Sorted(maps.Keys(colour.AllowedFamilies())) {
+		f, err := colour.GetFamily(fName)
+		if err != nil {
+			continue
+		}
+
+		if n, err := f.DistinctColourCount(); err == nil && n > maxLen {
+			large = append(large, fmt.Sprintf("%s (%d colours)", fName, n))
+		}
+	}
+
+	return large
+}
+
+// familyEntries returns the colours from the named family in a
+// deterministic order
+func familyEntries(entry, fName string) ([]paletteEntry, error) {
+	fName = strings.ToLower(strings.TrimSpace(fName))
+	if familyAliases.IsAnAlias(fName) {
+		fName = familyAliases.AliasVal(fName)[0]
+	}
+
+	f, err := colour.GetFamily(fName)
+	if err != nil {
+		return nil, err
+	}
+
+	colours, err := f.AllColours()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(colours, colour.RGBACompare)
+
+	entries := make([]paletteEntry, 0, len(colours))
+	for _, c := range colours {
+		entries = append(entries, paletteEntry{c: c, source: entry})
+	}
+
+	return entries, nil
+}
+
+// fileEntries returns the colours listed in the named file. There should be
+// one colour per line, blank lines and lines starting with "//" are
+// ignored.
+func (s Palette) fileEntries(fName string) ([]paletteEntry, error) {
+	content, err := os.ReadFile(fName) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the palette file: %w", err)
+	}
+
+	entries := []paletteEntry{}
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, paletteFileCommentIntro) {
+			continue
+		}
+
+		c, err := s.parser().parseRGBA(line)
+		if err != nil {
+			return nil, fmt.Errorf("palette file %q, line %d: %w",
+				fName, i+1, err)
+		}
+
+		entries = append(entries,
+			paletteEntry{
+				c:      c,
+				source: fmt.Sprintf("%s:%d", fName, i+1),
+			})
+	}
+
+	return entries, nil
+}
+
+// entries returns the palette entries for a single list element
+func (s Palette) entries(entry string) ([]paletteEntry, error) {
+	trimmed := strings.TrimSpace(entry)
+	lc := strings.ToLower(trimmed)
+
+	if p, ok := builtinPalettes[lc]; ok {
+		entries := make([]paletteEntry, 0, len(p))
+		for _, c := range p {
+			entries = append(entries,
+				paletteEntry{
+					c:      color.RGBAModel.Convert(c).(color.RGBA), //nolint:misspell,forcetypeassert
+					source: lc,
+				})
+		}
+
+		return entries, nil
+	}
+
+	if strings.HasPrefix(lc, paletteFamilyPrefix) {
+		return familyEntries(trimmed, trimmed[len(paletteFamilyPrefix):])
+	}
+
+	if strings.HasPrefix(lc, paletteFilePrefix) {
+		return s.fileEntries(trimmed[len(paletteFilePrefix):])
+	}
+
+	if strings.HasPrefix(lc, distinctPrefix) ||
+		strings.HasPrefix(lc, distinctFuncPrefix) {
+		colours, err := s.parser().parseDistinct(trimmed)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]paletteEntry, 0, len(colours))
+		for _, c := range colours {
+			entries = append(entries, paletteEntry{c: c, source: trimmed})
+		}
+
+		return entries, nil
+	}
+
+	if strings.HasPrefix(lc, scalePrefix) {
+		cs, err := s.parser().parseScale(trimmed)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]paletteEntry, 0, len(cs.Colours))
+		for i, c := range cs.Colours {
+			entries = append(entries,
+				paletteEntry{c: c, source: trimmed + "[" + cs.Keys[i] + "]"})
+		}
+
+		return entries, nil
+	}
+
+	if strings.HasPrefix(lc, harmonyPrefix) {
+		ncs, err := s.parser().parseHarmony(trimmed)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]paletteEntry, 0, len(ncs))
+		for _, nc := range ncs {
+			entries = append(entries,
+				paletteEntry{c: nc.Colour(), source: nc.Name()})
+		}
+
+		return entries, nil
+	}
+
+	c, err := s.parser().parseRGBA(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return []paletteEntry{{c: c, source: trimmed}}, nil
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// list of palette entries and, if they are all valid and there are not too
+// many colours, sets the Value.
+func (s Palette) SetWithVal(_ string, paramVal string) error {
+	all := []paletteEntry{}
+
+	for i, entry := range splitColourList(paramVal, s.GetSeparator()) {
+		entries, err := s.entries(entry)
+		if err != nil {
+			return fmt.Errorf("bad palette entry %d (%q): %w", i+1, entry, err)
+		}
+
+		all = append(all, entries...)
+	}
+
+	p := color.Palette{}            //nolint:misspell
+	seen := map[color.RGBA]string{} //nolint:misspell
+	errs := []error{}
+	dupCount := 0
+	distinct := []cvdColour{}
+
+	for _, e := range all {
+		if prev, ok := seen[e.c]; ok {
+			if !s.AllowDuplicates {
+				dupCount++
+				if dupCount <= paletteMaxDupErrs {
+					errs = append(errs,
+						fmt.Errorf("duplicate colour %s from %q and %q",
+							ChannelOrderRGBA.Hex(e.c), prev, e.source))
+				}
+			}
+
+			continue
+		}
+
+		seen[e.c] = e.source
+		p = append(p, e.c)
+		distinct = append(distinct, cvdColour{name: e.source, c: e.c})
+	}
+
+	if dupCount > paletteMaxDupErrs {
+		errs = append(errs,
+			fmt.Errorf("... and %d more duplicate colours",
+				dupCount-paletteMaxDupErrs))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if len(p) > s.maxLen() {
+		return fmt.Errorf("the palette has too many colours: %d (max: %d)",
+			len(p), s.maxLen())
+	}
+
+	if s.CVDMinDeltaE > 0 {
+		if err := checkCVDDistinct(distinct, s.CVDMinDeltaE); err != nil {
+			return err
+		}
+	}
+
+	*s.Value = p
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Palette) AllowedValues() string {
+	largeFamilyNote := ""
+	if large := largeFamilies(s.maxLen()); len(large) > 0 {
+		largeFamilyNote = ". Note that some families have more than " +
+			fmt.Sprint(s.maxLen()) + " colours and so cannot be used: " +
+			strings.Join(large, ", ")
+	}
+
+	return s.ListValDesc("palette entries") +
+		" giving at most " + fmt.Sprint(s.maxLen()) + " distinct colours." +
+		" Each entry is either a colour (see below)" +
+		" or the name of a standard palette" +
+		" (" + palettePlan9 + " or " + paletteWebSafe + ")" +
+		" or " + paletteFamilyPrefix + " followed by a colour family name" +
+		" (all the colours in the family)" +
+		" or " + paletteFilePrefix + " followed by the name of a file" +
+		" having one colour per line" +
+		" (blank lines and lines starting with " +
+		paletteFileCommentIntro + " are ignored)" +
+		" or " + distinctAllowedValues() +
+		" or " + scaleAllowedValues() +
+		" or " + harmonyAllowedValues() +
+		largeFamilyNote +
+		"\n\n" +
+		"A colour is given as follows. " + s.parser().allowedValues()
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Palette) ValDescribe() string {
+	return "colours"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Palette) CurrentValue() string {
+	hexVals := []string{}
+
+	for i, c := range *s.Value {
+		if i == paletteMaxShown {
+			hexVals = append(hexVals, "...")
+			break
+		}
+
+		rgba := color.RGBAModel.Convert(c).(color.RGBA) //nolint:misspell,forcetypeassert
+		hexVals = append(hexVals, ChannelOrderRGBA.Hex(rgba))
+	}
+
+	return fmt.Sprintf("%d colours: %s",
+		len(*s.Value), strings.Join(hexVals, s.GetSeparator()))
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder,
+// MaxLen or CVDMinDeltaE is invalid. If the CVD is set the Value is
+// registered with it.
+func (s Palette) CheckSetter(name string) {
+	intro := name + ": coloursetter.Palette Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " Palette.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " Palette.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " Palette.ChannelOrder: " + err.Error())
+	}
+
+	if s.MaxLen < 0 {
+		panic(fmt.Sprintf("%s Palette.MaxLen: %d is negative",
+			intro, s.MaxLen))
+	}
+
+	if s.CVDMinDeltaE < 0 {
+		panic(fmt.Sprintf("%s Palette.CVDMinDeltaE: %g is negative",
+			intro, s.CVDMinDeltaE))
+	}
+
+	s.CVD.register(s.Value, func(t CVDType) {
+		for i, c := range *s.Value {
+			rgba := color.RGBAModel.Convert(c).(color.RGBA) //nolint:misspell,forcetypeassert
+			(*s.Value)[i] = SimulateCVD(rgba, t)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"strings"
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestPaletteSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      Palette
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("colours"),
+			v:      "red,RGB{G: 0xff, B: 0xff},#00f",
+			expVal: "3 colours: #ff0000ff,#00ffffff,#0000ffff",
+		},
+		{
+			ID: testhelper.MkID("websafe"),
+			v:  "websafe",
+			expVal: "216 colours: #000000ff,#000033ff,#000066ff,#000099ff," +
+				"#0000ccff,#0000ffff,#003300ff,#003333ff,#003366ff," +
+				"#003399ff,#0033ccff,#0033ffff,#006600ff,#006633ff," +
+				"#006666ff,#006699ff,...",
+		},
+		{
+			ID: testhelper.MkID("plan9 and a colour - too many"),
+			ExpErr: testhelper.MkExpErr(
+				"the palette has too many colours: 257 (max: 256)"),
+			s: Palette{AllowDuplicates: true},
+			v: "plan9,#123457",
+		},
+		{
+			ID: testhelper.MkID("family"),
+			s:  Palette{StrListSeparator: psetter.StrListSeparator{Sep: ";"}},
+			v:  "family:CGA",
+			expVal: "16 colours: #000000ff;#008000ff;#00ff00ff;#000080ff;" +
+				"#008080ff;#0000ffff;#00ffffff;#800000ff;#808000ff;" +
+				"#800080ff;#808080ff;#c0c0c0ff;#ff0000ff;#ffff00ff;" +
+				"#ff00ffff;#ffffffff",
+		},
+		{
+			ID:     testhelper.MkID("file"),
+			v:      "file:testdata/Palette/good.txt",
+			expVal: "3 colours: #000000ff,#ffffffff,#ff0000ff",
+		},
+		{
+			ID: testhelper.MkID("bad file entry"),
+			ExpErr: testhelper.MkExpErr(
+				`bad palette entry 1 ("file:testdata/Palette/bad.txt"):`,
+				`palette file "testdata/Palette/bad.txt", line 2:`,
+				`bad colour name: "nonesuch"`),
+			v: "file:testdata/Palette/bad.txt",
+		},
+		{
+			ID: testhelper.MkID("duplicates"),
+			ExpErr: testhelper.MkExpErr(
+				`duplicate colour #ff0000ff from "red" and "#f00"`),
+			v: "red,#f00",
+		},
+		{
+			ID:     testhelper.MkID("duplicates allowed"),
+			s:      Palette{AllowDuplicates: true},
+			v:      "red,#f00,blue",
+			expVal: "2 colours: #ff0000ff,#0000ffff",
+		},
+		{
+			ID: testhelper.MkID("too many"),
+			ExpErr: testhelper.MkExpErr(
+				"the palette has too many colours: 3 (max: 2)"),
+			s: Palette{MaxLen: 2},
+			v: "red,green,blue",
+		},
+		{
+			ID: testhelper.MkID("many duplicates"),
+			ExpErr: testhelper.MkExpErr(
+				`duplicate colour #000000ff from "plan9" and "websafe"`,
+				"... and 20 more duplicate colours"),
+			v: "plan9,websafe",
+		},
+		{
+			ID: testhelper.MkID("large family - too many"),
+			ExpErr: testhelper.MkExpErr(
+				"the palette has too many colours: 503 (max: 256)"),
+			v: "family:x11",
+		},
+		{
+			ID: testhelper.MkID("large family"),
+			s:  Palette{MaxLen: 503},
+			v:  "family:x11",
+			expVal: "503 colours: #000000ff,#006400ff,#008b00ff,#00cd00ff," +
+				"#00ee00ff,#00ff00ff,#008b45ff,#00cd66ff,#00ee76ff," +
+				"#00ff7fff,#000080ff,#00008bff,#00688bff,#00868bff," +
+				"#008b8bff,#00fa9aff,...",
+		},
+		{
+			ID: testhelper.MkID("bad family"),
+			ExpErr: testhelper.MkExpErr(
+				`bad palette entry 2 ("family:nonesuch"):`),
+			v: "red,family:nonesuch",
+		},
+	}
+
+	for _, tc := range testCases {
+		var p color.Palette //nolint:misspell
+
+		s := tc.s
+		s.Value = &p
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestPaletteAllowedValuesLargeFamilies(t *testing.T) {
+	var p color.Palette //nolint:misspell
+
+	const x11Note = "x11 (503 colours)"
+
+	av := Palette{Value: &p}.AllowedValues()
+	if !strings.Contains(av, "cannot be used: ") ||
+		!strings.Contains(av, x11Note) {
+		t.Errorf("the allowed values should say that %s is too large", x11Note)
+	}
+
+	av = Palette{Value: &p, MaxLen: 503}.AllowedValues() //nolint:mnd
+	if strings.Contains(av, x11Note) {
+		t.Errorf("the allowed values should not mention %s", x11Note)
+	}
+}
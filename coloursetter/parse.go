@@ -0,0 +1,179 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+)
+
+// colourParser holds the settings which control how a colour value is
+// parsed. It extends the grammar supported by colour.ParseNamedColour with
+// the additional notations supported by this package.
+type colourParser struct {
+	families     colour.Families
+	channelOrder ChannelOrder
+	coefficients LumaCoefficients
+}
+
+// notation describes one of the additional colour notations. The notation
+// is recognised by its prefix (typically a name followed by a colon) which
+// is matched case-blind.
+type notation struct {
+	prefix string
+	parse  func(p colourParser, val string) (color.RGBA, error) //nolint:misspell
+	desc   string
+}
+
+// notations returns the additional colour notations recognised by the
+// parser. The prefixes must not clash with any colour family names.
+func (p colourParser) notations() []notation {
+	ns := []notation{
+		{
+			prefix: x11RGBPrefix,
+			parse: func(_ colourParser, val string) (color.RGBA, error) { //nolint:misspell
+				return parseX11RGB(val)
+			},
+			desc: "an X11 colour: " + x11RGBPrefix +
+				" followed by red, green and blue values" +
+				" separated by slashes (/)," +
+				" each having from 1 to 4 hexadecimal digits" +
+				" (for instance, " + x11RGBPrefix + "ffff/8080/0)",
+		},
+		{
+			prefix: x11RGBIPrefix,
+			parse: func(_ colourParser, val string) (color.RGBA, error) { //nolint:misspell
+				return parseX11RGBI(val)
+			},
+			desc: "an X11 colour: " + x11RGBIPrefix +
+				" followed by red, green and blue intensities" +
+				" separated by slashes (/)," +
+				" each in the range 0.0 to 1.0" +
+				" (for instance, " + x11RGBIPrefix + "1.0/0.5/0.0)",
+		},
+		{
+			prefix: ycbcrPrefix,
+			parse: func(p colourParser, val string) (color.RGBA, error) { //nolint:misspell
+				return parseYCbCr(val, p.coefficients)
+			},
+			desc: "a full-range Y'CbCr colour: ycbcr(y, cb, cr)" +
+				" with each value in the range 0-255," +
+				" converted using the " + p.coefficients.String() +
+				" coefficients",
+		},
+		{
+			prefix: imageSamplePrefix,
+			parse: func(_ colourParser, val string) (color.RGBA, error) { //nolint:misspell
+				return parseImageSample(val)
+			},
+			desc: "a colour sampled from an image file (PNG, JPEG or GIF): " +
+				imageSamplePrefix + "file@x,y giving the colour of a pixel" +
+				" or " + imageSamplePrefix + "file@x,y,w,h giving the" +
+				" average colour of a region." +
+				" The coordinates are from the top-left corner of the image",
+		},
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(channelOrders)) {
+		co := channelOrders[name]
+		ns = append(ns, notation{
+			prefix: name + ":",
+			parse: func(_ colourParser, val string) (color.RGBA, error) { //nolint:misspell
+				return parseOrderedColour(co, val)
+			},
+		})
+	}
+
+	return ns
+}
+
+// findNotation returns the notation matching the start of the string and
+// the remainder of the string following the prefix. It returns false if no
+// notation matches.
+func (p colourParser) findNotation(s string) (notation, string, bool) {
+	trimmed := strings.TrimSpace(s)
+	lc := strings.ToLower(trimmed)
+
+	for _, n := range p.notations() {
+		if strings.HasPrefix(lc, n.prefix) {
+			return n, trimmed[len(n.prefix):], true
+		}
+	}
+
+	return notation{}, "", false
+}
+
+// parse converts the string into a NamedColour. The name is the string as
+// given. In addition to the forms accepted by colour.ParseNamedColour it
+// accepts hex colours and integer colours in the parser's channel order and
+// values with an explicit channel order prefix.
+func (p colourParser) parse(s string) (colour.NamedColour, error) {
+	c, err := p.parseRGBA(s)
+	if err != nil {
+		return colour.NamedColour{}, err
+	}
+
+	return colour.MakeNamedColour(s, c), nil
+}
+
+// parseRGBA converts the string into a colour. See parse for details.
+func (p colourParser) parseRGBA(s string) (color.RGBA, error) { //nolint:misspell
+	if n, val, ok := p.findNotation(s); ok {
+		return n.parse(p, val)
+	}
+
+	trimmed := strings.TrimSpace(s)
+	if hexColourRE.MatchString(trimmed) || intColourRE.MatchString(trimmed) {
+		return parseOrderedColour(p.channelOrder, trimmed)
+	}
+
+	nc, err := colour.ParseNamedColour(p.families, s)
+
+	return nc.Colour(), err
+}
+
+// intRGBAllowedValues describes how short integer colours are taken with
+// the RGBA channel order
+func (p colourParser) intRGBAllowedValues() string {
+	if p.channelOrder.channels() != string(ChannelOrderRGBA) {
+		return ""
+	}
+
+	return " (an integer of up to " + strconv.Itoa(maxRGBIntDigits) +
+		" digits is taken as 0xRRGGBB and is fully opaque)"
+}
+
+// allowedValues returns a string describing the values which the parser
+// will accept.
+func (p colourParser) allowedValues() string {
+	var extra strings.Builder
+
+	for _, n := range p.notations() {
+		if n.desc != "" {
+			extra.WriteString("\n\nOr " + n.desc)
+		}
+	}
+
+	alphaDigits := ""
+	if p.channelOrder.hasAlpha() {
+		alphaDigits = " (or 4 or 8 digits to include the alpha channel)"
+	}
+
+	return colour.NamedColourAllowedValues(p.families) +
+		alphaDigits +
+		" taken in the channel order " + p.channelOrder.String() +
+		"\n\n" +
+		"Or a hexadecimal integer with a leading 0x" +
+		" taking the channels (in the order " + p.channelOrder.String() +
+		") from the most significant byte downwards" +
+		p.intRGBAllowedValues() +
+		"\n\n" +
+		"Or a channel order, a colon (:) and hex digits or an integer." +
+		" The channel order is one of: " +
+		strings.Join(slices.Sorted(maps.Keys(channelOrders)), ", ") +
+		" (kml is the same as abgr)" +
+		extra.String()
+}
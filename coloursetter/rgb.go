@@ -2,6 +2,7 @@ package coloursetter
 
 import (
 	"image/color" //nolint:misspell
+	"math"
 
 	"github.com/nickwells/colour.mod/v2/colour"
 	"github.com/nickwells/param.mod/v7/psetter"
@@ -15,14 +16,73 @@ type RGB struct {
 
 	Value    *color.RGBA
 	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// ShowHex, if set, makes CurrentValue show the colour as a hash
+	// followed by hexadecimal digits in the ChannelOrder.
+	ShowHex bool
+	// ShowNearest, if set, makes CurrentValue show the colour in
+	// hexadecimal followed by the perceptually nearest colour from the
+	// Families and its distance from the colour.
+	ShowNearest bool
+	// NearestBy gives the formula used to find the nearest colour. If it is
+	// not set the CIEDE2000 formula is used.
+	NearestBy DeltaEFormula
+	// Scope, if set, supplies the families used in place of the Families
+	// and defers the interpretation of colour names until all the
+	// parameters have been parsed (see FamilyScope).
+	Scope *FamilyScope
+	// Refs, if set, allows the value to be given as a reference to another
+	// colour parameter registered with the same ColourRefs (see
+	// ColourRefs). The parameter is registered with the ColourRefs when it
+	// is added to the param set.
+	Refs *ColourRefs
+	// CVD, if set, has the Value transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+}
+
+// parser returns the colourParser for this setter
+func (s RGB) parser() colourParser {
+	return colourParser{
+		families:     s.Scope.families(s.Families),
+		channelOrder: s.ChannelOrder,
+	}
 }
 
 // SetWithVal (called with the value following the parameter) either parses
 // the RGB value or else looks up the supplied colour name. The search is
 // performed "case-blind" - all names are mapped to their lower-case
 // equivalents.
-func (s RGB) SetWithVal(_ string, paramVal string) error {
-	nc, err := colour.ParseNamedColour(s.Families, paramVal)
+//
+// If the Scope is set the value is interpreted again, using the final
+// setting of the Scope's families, once all the parameters have been
+// parsed and any error is reported then.
+//
+// If the Refs is set and the value is a reference to another colour
+// parameter the Value is set once all the parameters have been parsed.
+func (s RGB) SetWithVal(paramName string, paramVal string) error {
+	if isRef, err := s.Refs.setRef(paramName, paramVal); isRef {
+		return err
+	}
+
+	if s.Scope != nil {
+		s.Scope.record(paramName,
+			func() error { return s.set(paramVal) })
+
+		return nil
+	}
+
+	return s.set(paramVal)
+}
+
+// set parses the value and, if it is valid, sets the Value
+func (s RGB) set(paramVal string) error {
+	nc, err := s.parser().parse(paramVal)
 	if err == nil {
 		*s.Value = nc.Colour()
 	}
@@ -32,7 +92,11 @@ func (s RGB) SetWithVal(_ string, paramVal string) error {
 
 // AllowedValues returns a string describing the allowed values
 func (s RGB) AllowedValues() string {
-	return colour.NamedColourAllowedValues(s.Families)
+	if s.Refs != nil {
+		return s.parser().allowedValues() + refAllowedValues()
+	}
+
+	return s.parser().allowedValues()
 }
 
 // ValDescribe returns a string describing the value that can follow the
@@ -41,15 +105,45 @@ func (s RGB) ValDescribe() string {
 	return "colour"
 }
 
-// CurrentValue returns the current setting of the parameter value
+// CurrentValue returns the current setting of the parameter value. If the
+// value was given as a reference to another colour parameter the reference
+// is shown before the colour.
 func (s RGB) CurrentValue() string {
+	return s.Refs.currentValue(s.Value, s.currentColour())
+}
+
+// currentColour returns the current colour in the form given by the
+// ShowHex and ShowNearest settings
+func (s RGB) currentColour() string {
+	if s.ShowNearest {
+		cv := s.ChannelOrder.Hex(*s.Value)
+		if !s.ShowHex && s.Value.A == math.MaxUint8 {
+			cv = ChannelOrderRGB.Hex(*s.Value)
+		}
+
+		n, err := FindNearest(
+			s.Scope.families(s.Families), *s.Value, s.NearestBy)
+		if err != nil {
+			return cv
+		}
+
+		return cv + " (" + n.String() + ")"
+	}
+
+	if s.ShowHex {
+		return s.ChannelOrder.Hex(*s.Value)
+	}
+
 	return colour.Describe(*s.Value)
 }
 
 // CheckSetter panics if the setter has not been properly created - if the
-// Value is nil or the Families value is incorrect. Possible problems with
-// the Families member include duplicate Families in the set or an invalid
-// Family constant being used.
+// Value is nil or the Families value is incorrect or the ChannelOrder or
+// NearestBy formula is invalid or the Scope has a nil Value. Possible
+// problems with the Families member include duplicate Families in the set or
+// an invalid Family constant being used. If the CVD is set the Value is
+// registered with it. If the Refs is set the Value is registered with it
+// under the name.
 func (s RGB) CheckSetter(name string) {
 	intro := name + ": coloursetter.RGB Check failed:"
 
@@ -60,4 +154,19 @@ func (s RGB) CheckSetter(name string) {
 	if err := s.Families.Check(); err != nil {
 		panic(intro + " RGB.Families: " + err.Error())
 	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " RGB.ChannelOrder: " + err.Error())
+	}
+
+	if err := s.Scope.check(); err != nil {
+		panic(intro + " RGB.Scope: " + err.Error())
+	}
+
+	if err := s.NearestBy.Check(); err != nil {
+		panic(intro + " RGB.NearestBy: " + err.Error())
+	}
+
+	s.CVD.registerRGB(s.Value)
+	s.Refs.registerRGB(name, s.Value)
 }
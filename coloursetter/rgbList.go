@@ -0,0 +1,135 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// RGBList is used to set a list of colours. The value is given as for the
+// Palette setter, with the same constraints on the length of the list and
+// on duplicate colours, and each colour is converted to a color.RGBA.
+//
+//nolint:misspell
+type RGBList struct {
+	psetter.ValueReqMandatory
+
+	Value    *[]color.RGBA
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// MaxLen gives the maximum number of entries allowed in the list. If
+	// it is not set DfltPaletteMaxLen is used.
+	MaxLen int
+	// AllowDuplicates, if set, causes duplicate colours to be silently
+	// removed from the list, otherwise they are reported as errors.
+	AllowDuplicates bool
+	// CVDMinDeltaE, if greater than zero, causes the list to be rejected if
+	// any two of its colours, as seen by someone with protanopia,
+	// deuteranopia or tritanopia, differ by less than this (CIEDE2000)
+	// Delta E.
+	CVDMinDeltaE float64
+	// CVD, if set, has the colours transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+	// The StrListSeparator allows you to override the default separator
+	// between list elements.
+	psetter.StrListSeparator
+}
+
+// palette returns the Palette setter used to parse the list
+func (s RGBList) palette(v *color.Palette) Palette { //nolint:misspell
+	return Palette{
+		Value:            v,
+		Families:         s.Families,
+		ChannelOrder:     s.ChannelOrder,
+		MaxLen:           s.MaxLen,
+		AllowDuplicates:  s.AllowDuplicates,
+		CVDMinDeltaE:     s.CVDMinDeltaE,
+		StrListSeparator: s.StrListSeparator,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// list of entries and, if they are all valid and there are not too many
+// colours, sets the Value.
+func (s RGBList) SetWithVal(paramName string, paramVal string) error {
+	var p color.Palette //nolint:misspell
+
+	if err := s.palette(&p).SetWithVal(paramName, paramVal); err != nil {
+		return err
+	}
+
+	colours := make([]color.RGBA, 0, len(p)) //nolint:misspell
+	for _, c := range p {
+		colours = append(colours,
+			color.RGBAModel.Convert(c).(color.RGBA)) //nolint:misspell,forcetypeassert
+	}
+
+	*s.Value = colours
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s RGBList) AllowedValues() string {
+	return s.palette(nil).AllowedValues()
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s RGBList) ValDescribe() string {
+	return "colours"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s RGBList) CurrentValue() string {
+	p := make(color.Palette, 0, len(*s.Value)) //nolint:misspell
+	for _, c := range *s.Value {
+		p = append(p, c)
+	}
+
+	return s.palette(&p).CurrentValue()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder,
+// MaxLen or CVDMinDeltaE is invalid. If the CVD is set the Value is
+// registered with it.
+func (s RGBList) CheckSetter(name string) {
+	intro := name + ": coloursetter.RGBList Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " RGBList.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " RGBList.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " RGBList.ChannelOrder: " + err.Error())
+	}
+
+	if s.MaxLen < 0 {
+		panic(fmt.Sprintf("%s RGBList.MaxLen: %d is negative",
+			intro, s.MaxLen))
+	}
+
+	if s.CVDMinDeltaE < 0 {
+		panic(fmt.Sprintf("%s RGBList.CVDMinDeltaE: %g is negative",
+			intro, s.CVDMinDeltaE))
+	}
+
+	s.CVD.register(s.Value, func(t CVDType) {
+		for i, c := range *s.Value {
+			(*s.Value)[i] = SimulateCVD(c, t)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestRGBListSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      RGBList
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("colours"),
+			v:      "red,RGB{G: 0xff, B: 0xff},#00f8",
+			expVal: "3 colours: #ff0000ff,#00ffffff,#0000ff88",
+		},
+		{
+			ID:     testhelper.MkID("scale, with a separator"),
+			s:      RGBList{StrListSeparator: psetter.StrListSeparator{Sep: ";"}},
+			v:      "white;scale:navy:2",
+			expVal: "3 colours: #ffffffff;#f0f5ffff;#000080ff",
+		},
+		{
+			ID: testhelper.MkID("duplicates"),
+			ExpErr: testhelper.MkExpErr(
+				`duplicate colour #ff0000ff from "red" and "#f00"`),
+			v: "red,#f00",
+		},
+		{
+			ID:     testhelper.MkID("duplicates allowed"),
+			s:      RGBList{AllowDuplicates: true},
+			v:      "red,#f00,blue",
+			expVal: "2 colours: #ff0000ff,#0000ffff",
+		},
+		{
+			ID: testhelper.MkID("too many"),
+			ExpErr: testhelper.MkExpErr(
+				"the palette has too many colours: 3 (max: 2)"),
+			s: RGBList{MaxLen: 2},
+			v: "red,green,blue",
+		},
+		{
+			ID: testhelper.MkID("bad colour"),
+			ExpErr: testhelper.MkExpErr(
+				`bad palette entry 2 ("nonesuch"):`),
+			v: "red,nonesuch",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v []color.RGBA //nolint:misspell
+
+		s := tc.s
+		s.Value = &v
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestRGBListCheckSetter(t *testing.T) {
+	var v []color.RGBA //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		s RGBList
+	}{
+		{
+			ID: testhelper.MkID("nil Value"),
+			ExpPanic: testhelper.MkExpPanic(
+				"test: coloursetter.RGBList Check failed: RGBList.Value: is nil"),
+		},
+		{
+			ID: testhelper.MkID("negative MaxLen"),
+			ExpPanic: testhelper.MkExpPanic(
+				"test: coloursetter.RGBList Check failed:" +
+					" RGBList.MaxLen: -1 is negative"),
+			s: RGBList{Value: &v, MaxLen: -1},
+		},
+		{
+			ID: testhelper.MkID("good"),
+			s:  RGBList{Value: &v},
+		},
+	}
+
+	for _, tc := range testCases {
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			tc.s.CheckSetter("test")
+		})
+		testhelper.CheckExpPanic(t, panicked, panicVal, tc)
+	}
+}
@@ -0,0 +1,180 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"slices"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// rgbMapKeySep separates the name from the colour in an RGBMap entry
+const rgbMapKeySep = "="
+
+// RGBMap is used to set a map of names to colours. The value is a list of
+// entries, each of which is a name, an equals sign and a colour (as
+// accepted by the RGB setter), for instance, "error=red,warning=#ffa500".
+// The map is replaced by the entries given.
+//
+//nolint:misspell
+type RGBMap struct {
+	psetter.ValueReqMandatory
+
+	Value    *map[string]color.RGBA
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// CVDMinDeltaE, if greater than zero, causes the map to be rejected
+	// if any two of its colours, as seen by someone with protanopia,
+	// deuteranopia or tritanopia, differ by less than this (CIEDE2000)
+	// Delta E.
+	CVDMinDeltaE float64
+	// CVD, if set, has the colours transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+	// The StrListSeparator allows you to override the default separator
+	// between list elements.
+	psetter.StrListSeparator
+}
+
+// parser returns the colourParser for this setter
+func (s RGBMap) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// list of entries and, if they are all valid, sets the Value.
+func (s RGBMap) SetWithVal(_ string, paramVal string) error {
+	m := map[string]color.RGBA{} //nolint:misspell
+	errs := []error{}
+	distinct := []cvdColour{}
+
+	for i, entry := range splitColourList(paramVal, s.GetSeparator()) {
+		name, colourStr, found := strings.Cut(entry, rgbMapKeySep)
+		name = strings.TrimSpace(name)
+
+		switch {
+		case !found:
+			errs = append(errs,
+				fmt.Errorf("bad entry %d (%q): expected name%scolour",
+					i+1, entry, rgbMapKeySep))
+
+			continue
+		case name == "":
+			errs = append(errs,
+				fmt.Errorf("bad entry %d (%q): the name is missing",
+					i+1, entry))
+
+			continue
+		}
+
+		if _, ok := m[name]; ok {
+			errs = append(errs,
+				fmt.Errorf("bad entry %d (%q): duplicate name %q",
+					i+1, entry, name))
+
+			continue
+		}
+
+		c, err := s.parser().parseRGBA(colourStr)
+		if err != nil {
+			errs = append(errs,
+				fmt.Errorf("bad entry %d (%q): %w", i+1, entry, err))
+
+			continue
+		}
+
+		m[name] = c
+		distinct = append(distinct, cvdColour{name: name, c: c})
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if s.CVDMinDeltaE > 0 {
+		if err := checkCVDDistinct(distinct, s.CVDMinDeltaE); err != nil {
+			return err
+		}
+	}
+
+	*s.Value = m
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s RGBMap) AllowedValues() string {
+	return s.ListValDesc("entries") +
+		". Each entry is a name followed by " + rgbMapKeySep +
+		" and a colour and each name may be given only once" +
+		cvdAllowedValues("the colours", s.CVDMinDeltaE) +
+		"\n\n" +
+		"A colour is given as follows. " + s.parser().allowedValues()
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s RGBMap) ValDescribe() string {
+	return "name" + rgbMapKeySep + "colour,..."
+}
+
+// CurrentValue returns the current setting of the parameter value, the
+// entries are shown in name order
+func (s RGBMap) CurrentValue() string {
+	names := make([]string, 0, len(*s.Value))
+	for name := range *s.Value {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	entries := make([]string, 0, len(names))
+	for _, name := range names {
+		entries = append(entries,
+			name+rgbMapKeySep+s.ChannelOrder.Hex((*s.Value)[name]))
+	}
+
+	return strings.Join(entries, s.GetSeparator())
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder is
+// invalid or the CVDMinDeltaE is negative. If the CVD is set the Value is
+// registered with it.
+func (s RGBMap) CheckSetter(name string) {
+	intro := name + ": coloursetter.RGBMap Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " RGBMap.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " RGBMap.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " RGBMap.ChannelOrder: " + err.Error())
+	}
+
+	if s.CVDMinDeltaE < 0 {
+		panic(fmt.Sprintf("%s RGBMap.CVDMinDeltaE: %g is negative",
+			intro, s.CVDMinDeltaE))
+	}
+
+	s.CVD.register(s.Value, func(t CVDType) {
+		for k, c := range *s.Value {
+			(*s.Value)[k] = SimulateCVD(c, t)
+		}
+	})
+}
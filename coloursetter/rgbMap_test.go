@@ -0,0 +1,107 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestRGBMapSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      RGBMap
+		val    string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("good entries"),
+			val:    "warning=#ffa500, error = red",
+			expVal: "error=#ff0000ff,warning=#ffa500ff",
+		},
+		{
+			ID:     testhelper.MkID("bracketed colour"),
+			val:    "dim=RGB{R: 0x80, G: 0x80, B: 0x80},bright=white",
+			expVal: "bright=#ffffffff,dim=#808080ff",
+		},
+		{
+			ID: testhelper.MkID("bad entries"),
+			ExpErr: testhelper.MkExpErr(
+				`bad entry 1 ("red"): expected name=colour`,
+				`bad entry 2 ("=red"): the name is missing`,
+				`bad entry 4 ("a=blue"): duplicate name "a"`,
+				`bad entry 5 ("b=nosuchcolour"): bad colour name`),
+			val:    "red,=red,a=red,a=blue,b=nosuchcolour",
+			expVal: "old=#000000ff",
+		},
+		{
+			ID: testhelper.MkID("hard to tell apart"),
+			ExpErr: testhelper.MkExpErr(`the colours "a" and "b"` +
+				" are hard to tell apart with protanopia"),
+			s:      RGBMap{CVDMinDeltaE: 10},
+			val:    "a=red,b=green",
+			expVal: "old=#000000ff",
+		},
+		{
+			ID:     testhelper.MkID("easy to tell apart"),
+			s:      RGBMap{CVDMinDeltaE: 10},
+			val:    "a=black,b=white",
+			expVal: "a=#000000ff,b=#ffffffff",
+		},
+	}
+
+	for _, tc := range testCases {
+		m := map[string]color.RGBA{"old": {A: 0xff}} //nolint:misspell
+		s := tc.s
+		s.Value = &m
+
+		err := s.SetWithVal("test", tc.val)
+		testhelper.CheckExpErr(t, err, tc)
+		testhelper.DiffString(t, tc.IDStr(), "value",
+			s.CurrentValue(), tc.expVal)
+	}
+}
+
+func TestRGBMapCheck(t *testing.T) {
+	m := map[string]color.RGBA{} //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		v RGBMap
+	}{
+		{
+			ID: testhelper.MkID("No panic expected"),
+			v:  RGBMap{Value: &m},
+		},
+		{
+			ID: testhelper.MkID("Panic expected, nil Value"),
+			ExpPanic: testhelper.MkExpPanic(
+				"test-param: coloursetter.RGBMap Check failed:" +
+					" RGBMap.Value: is nil"),
+			v: RGBMap{},
+		},
+		{
+			ID: testhelper.MkID("Panic expected, bad ChannelOrder"),
+			ExpPanic: testhelper.MkExpPanic(
+				"test-param: coloursetter.RGBMap Check failed:" +
+					` RGBMap.ChannelOrder: "XYZ" is not a valid ChannelOrder`),
+			v: RGBMap{Value: &m, ChannelOrder: "XYZ"},
+		},
+		{
+			ID: testhelper.MkID("Panic expected, negative CVDMinDeltaE"),
+			ExpPanic: testhelper.MkExpPanic(
+				"test-param: coloursetter.RGBMap Check failed:" +
+					" RGBMap.CVDMinDeltaE: -1 is negative"),
+			v: RGBMap{Value: &m, CVDMinDeltaE: -1},
+		},
+	}
+
+	for _, tc := range testCases {
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			tc.v.CheckSetter("test-param")
+		})
+		testhelper.CheckExpPanic(t, panicked, panicVal, tc)
+	}
+}
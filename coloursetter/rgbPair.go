@@ -2,14 +2,40 @@ package coloursetter
 
 import (
 	"errors"
+	"fmt"
 	"image/color" //nolint:misspell
+	"math"
+	"slices"
 	"strings"
 
 	"github.com/nickwells/colour.mod/v2/colour"
 	"github.com/nickwells/param.mod/v7/psetter"
 )
 
-// RGBPair is used to set a pair of colour value
+// pairAuto is the value given in place of one of an RGBPair's colours to
+// have it chosen automatically
+const pairAuto = "auto"
+
+// pairAutoColours holds the default candidates for an automatically chosen
+// colour
+var pairAutoColours = []color.RGBA{ //nolint:misspell
+	{A: math.MaxUint8},
+	{R: math.MaxUint8, G: math.MaxUint8, B: math.MaxUint8, A: math.MaxUint8},
+}
+
+// RGBPair is used to set a pair of colour value. Either colour (but not
+// both) may be given as "auto" in which case it is chosen to give the
+// strongest contrast with the other colour. The first colour is taken as
+// the foreground (text) colour and the second as the background. Unlike
+// the RGB setter, the colours cannot be given as references to other
+// colour parameters (see ColourRefs).
+//
+// Note that, unlike the other setters, the RGBPair methods have pointer
+// receivers as the setter records which, if either, of the colours was
+// chosen automatically. It must therefore be added to the param set by
+// pointer, for instance:
+//
+//	ps.Add("colours", &coloursetter.RGBPair{Value1: &fg, Value2: &bg}, ...)
 //
 //nolint:misspell
 type RGBPair struct {
@@ -18,61 +44,296 @@ type RGBPair struct {
 	Value1   *color.RGBA
 	Value2   *color.RGBA
 	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// ShowHex, if set, makes CurrentValue show the colours as a hash
+	// followed by hexadecimal digits in the ChannelOrder.
+	ShowHex bool
+	// CVDMinDeltaE, if greater than zero, causes the colours to be
+	// rejected if, as seen by someone with protanopia, deuteranopia or
+	// tritanopia, they differ by less than this (CIEDE2000) Delta E.
+	CVDMinDeltaE float64
+	// AutoCandidates gives the colours from which a colour given as "auto"
+	// is chosen. If neither this nor the AutoFamilies is set the choice is
+	// between black and white.
+	AutoCandidates []color.RGBA //nolint:misspell
+	// AutoFamilies gives the colour families from whose colours a colour
+	// given as "auto" is chosen. It cannot be used with AutoCandidates.
+	AutoFamilies colour.Families
+	// Contrast gives the metric used to choose a colour given as "auto"
+	// and to check the MinContrast. The default is the WCAG 2 contrast
+	// ratio.
+	Contrast ContrastMetric
+	// MinContrast, if greater than zero, causes the colours to be rejected
+	// if the contrast between them is less than this. For the WCAG 2
+	// contrast this is the contrast ratio (4.5 is recommended for normal
+	// text) and for APCA it is the absolute lightness contrast, Lc (75 is
+	// recommended for body text).
+	MinContrast float64
+	// Scope, if set, supplies the families used in place of the Families
+	// and defers the interpretation of colour names until all the
+	// parameters have been parsed (see FamilyScope).
+	Scope *FamilyScope
+	// CVD, if set, has the colours transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+
+	// auto1 and auto2 record whether the corresponding colour was chosen
+	// automatically and autoColour1 and autoColour2 the colour chosen
+	auto1, auto2             bool
+	autoColour1, autoColour2 color.RGBA
+}
+
+// parser returns the colourParser for this setter
+func (s *RGBPair) parser() colourParser {
+	return colourParser{
+		families:     s.Scope.families(s.Families),
+		channelOrder: s.ChannelOrder,
+	}
 }
 
 // SetWithVal (called with the value following the parameter) either parses
 // the RGB value or else looks up the supplied colour name. The search is
 // performed "case-blind" - all names are mapped to their lower-case
 // equivalents.
-func (s RGBPair) SetWithVal(_ string, paramVal string) error {
+//
+// If the Scope is set the value is interpreted again, using the final
+// setting of the Scope's families, once all the parameters have been
+// parsed and any error is reported then.
+func (s *RGBPair) SetWithVal(paramName string, paramVal string) error {
+	if s.Scope != nil {
+		s.Scope.record(paramName,
+			func() error { return s.set(paramVal) })
+
+		return nil
+	}
+
+	return s.set(paramVal)
+}
+
+// set parses the value and, if it is valid, sets the Value
+func (s *RGBPair) set(paramVal string) error {
 	colour1, colour2, ok := strings.Cut(paramVal, ";")
 	if !ok {
 		return errors.New("missing ';' - two colours separated by ; are needed")
 	}
 
-	{
-		nc, err := colour.ParseNamedColour(s.Families, colour1)
+	auto1 := strings.EqualFold(strings.TrimSpace(colour1), pairAuto)
+	auto2 := strings.EqualFold(strings.TrimSpace(colour2), pairAuto)
+
+	if auto1 && auto2 {
+		return errors.New("both colours are " + pairAuto +
+			" - at most one of the colours may be chosen automatically")
+	}
+
+	nc1, nc2, err := s.parseColours(colour1, colour2, auto1, auto2)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkContrast(nc1, nc2); err != nil {
+		return err
+	}
+
+	if s.CVDMinDeltaE > 0 {
+		err = checkCVDDistinct(
+			[]cvdColour{
+				{name: strings.TrimSpace(colour1), c: nc1.Colour()},
+				{name: strings.TrimSpace(colour2), c: nc2.Colour()},
+			},
+			s.CVDMinDeltaE)
 		if err != nil {
 			return err
 		}
+	}
+
+	*s.Value1 = nc1.Colour()
+	*s.Value2 = nc2.Colour()
+
+	s.auto1, s.auto2 = auto1, auto2
+	s.autoColour1, s.autoColour2 = *s.Value1, *s.Value2
+
+	return nil
+}
+
+// checkContrast returns a non-nil error if the MinContrast is set and the
+// contrast between the colours is less than it
+func (s *RGBPair) checkContrast(nc1, nc2 colour.NamedColour) error {
+	if s.MinContrast <= 0 {
+		return nil
+	}
+
+	return s.Contrast.checkMin(
+		strings.TrimSpace(nc1.Name()), strings.TrimSpace(nc2.Name()),
+		nc1.Colour(), nc2.Colour(), s.MinContrast)
+}
+
+// parseColours parses the two colours, choosing the colour given as auto to
+// contrast with the other
+func (s *RGBPair) parseColours(colour1, colour2 string, auto1, auto2 bool) (
+	colour.NamedColour, colour.NamedColour, error,
+) {
+	var nc1, nc2 colour.NamedColour
+
+	var err error
+
+	if !auto1 {
+		if nc1, err = s.parser().parse(colour1); err != nil {
+			return nc1, nc2, err
+		}
+	}
 
-		*s.Value1 = nc.Colour()
+	if !auto2 {
+		if nc2, err = s.parser().parse(colour2); err != nil {
+			return nc1, nc2, err
+		}
 	}
 
-	{
-		nc, err := colour.ParseNamedColour(s.Families, colour2)
+	if !auto1 && !auto2 {
+		return nc1, nc2, nil
+	}
+
+	candidates, err := s.autoCandidates()
+	if err != nil {
+		return nc1, nc2, err
+	}
+
+	switch {
+	case auto1:
+		nc1 = colour.MakeNamedColour(pairAuto,
+			s.Contrast.bestContrast(nc2.Colour(), candidates, true))
+	case auto2:
+		nc2 = colour.MakeNamedColour(pairAuto,
+			s.Contrast.bestContrast(nc1.Colour(), candidates, false))
+	}
+
+	return nc1, nc2, nil
+}
+
+// autoCandidates returns the colours from which a colour given as auto is
+// chosen. The colours from the AutoFamilies are sorted so that the choice
+// between colours giving equally strong contrast does not vary.
+func (s *RGBPair) autoCandidates() ([]color.RGBA, error) { //nolint:misspell
+	switch {
+	case len(s.AutoCandidates) > 0:
+		return s.AutoCandidates, nil
+	case len(s.AutoFamilies) > 0:
+		candidates, err := s.AutoFamilies.AllColours()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		*s.Value2 = nc.Colour()
+		slices.SortFunc(candidates, func(a, b color.RGBA) int { //nolint:misspell
+			return strings.Compare(ChannelOrderRGBA.Hex(a),
+				ChannelOrderRGBA.Hex(b))
+		})
+
+		return candidates, nil
 	}
 
-	return nil
+	return pairAutoColours, nil
 }
 
 // AllowedValues returns a string describing the allowed values
-func (s RGBPair) AllowedValues() string {
+func (s *RGBPair) AllowedValues() string {
+	autoFrom := "black or white"
+
+	switch {
+	case len(s.AutoCandidates) > 0:
+		autoFrom = fmt.Sprintf("one of %d colours", len(s.AutoCandidates))
+	case len(s.AutoFamilies) > 0:
+		autoFrom = "a colour from the " + s.AutoFamilies.String() +
+			" colour families"
+	}
+
 	return "a pair of colours separated by ';' where:" +
-		colour.NamedColourAllowedValues(s.Families)
+		s.parser().allowedValues() +
+		"\n\n" +
+		"Either colour (but not both) may be given as " + pairAuto +
+		" in which case " + autoFrom + " is chosen" +
+		" to give the strongest contrast with the other colour" +
+		" (using the " + s.Contrast.String() + " contrast)." +
+		" The first colour is taken as the text colour" +
+		" and the second as the background" +
+		s.contrastAllowedValues() +
+		cvdAllowedValues("the two colours", s.CVDMinDeltaE)
 }
 
 // ValDescribe returns a string describing the value that can follow the
 // parameter
-func (s RGBPair) ValDescribe() string {
+func (s *RGBPair) ValDescribe() string {
 	return "colour;colour"
 }
 
+// contrastAllowedValues describes the minimum contrast, if any
+func (s *RGBPair) contrastAllowedValues() string {
+	if s.MinContrast <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(". The %s contrast between the colours"+
+		" must be at least %g", s.Contrast, s.MinContrast)
+}
+
 // CurrentValue returns the current setting of the parameter value
-func (s RGBPair) CurrentValue() string {
-	return colour.Describe(*s.Value1) + ";" + colour.Describe(*s.Value2)
+func (s *RGBPair) CurrentValue() string {
+	return s.currentColour(*s.Value1, true) + ";" +
+		s.currentColour(*s.Value2, false)
+}
+
+// AutoChosen reports whether the first and second colours were chosen
+// automatically (and have not since been changed)
+func (s *RGBPair) AutoChosen() (first, second bool) {
+	return s.isAuto(*s.Value1, true), s.isAuto(*s.Value2, false)
+}
+
+// isAuto returns true if the first (or, if first is false, the second)
+// colour, v, was chosen automatically and has not since been changed
+func (s *RGBPair) isAuto(v color.RGBA, first bool) bool { //nolint:misspell
+	if first {
+		return s.auto1 && s.autoColour1 == v
+	}
+
+	return s.auto2 && s.autoColour2 == v
+}
+
+// currentColour returns the description of the first (or, if first is
+// false, the second) of the pair of colours. A colour chosen automatically
+// is shown as auto(...).
+func (s *RGBPair) currentColour(v color.RGBA, first bool) string { //nolint:misspell
+	desc := colour.Describe(v)
+	if s.ShowHex {
+		desc = s.ChannelOrder.Hex(v)
+	}
+
+	if s.isAuto(v, first) {
+		return pairAuto + "(" + desc + ")"
+	}
+
+	return desc
+}
+
+// simulateCVD transforms the colours to simulate the colour vision
+// deficiency. The record of any colour chosen automatically is transformed
+// in the same way so that it is still shown as auto(...).
+func (s *RGBPair) simulateCVD(t CVDType) {
+	*s.Value1 = SimulateCVD(*s.Value1, t)
+	*s.Value2 = SimulateCVD(*s.Value2, t)
+	s.autoColour1 = SimulateCVD(s.autoColour1, t)
+	s.autoColour2 = SimulateCVD(s.autoColour2, t)
 }
 
 // CheckSetter panics if the setter has not been properly created - if the
-// Value is nil or the Families value is incorrect. Possible problems with
-// the Families member include duplicate Families in the set or an invalid
-// Family constant being used.
-func (s RGBPair) CheckSetter(name string) {
+// Value is nil or the Families value is incorrect or the ChannelOrder is
+// invalid or the CVDMinDeltaE or MinContrast is negative or the Contrast is
+// invalid or the Scope has a nil Value. Possible problems with the Families
+// member include duplicate Families in the set or an invalid Family constant
+// being used. If the CVD is set the Values are registered with it.
+func (s *RGBPair) CheckSetter(name string) {
 	intro := name + ": coloursetter.RGB Check failed:"
 
 	if s.Value1 == nil {
@@ -86,4 +347,39 @@ func (s RGBPair) CheckSetter(name string) {
 	if err := s.Families.Check(); err != nil {
 		panic(intro + " RGB.Families: " + err.Error())
 	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " RGB.ChannelOrder: " + err.Error())
+	}
+
+	if err := s.Scope.check(); err != nil {
+		panic(intro + " RGB.Scope: " + err.Error())
+	}
+
+	if s.CVDMinDeltaE < 0 {
+		panic(fmt.Sprintf("%s RGB.CVDMinDeltaE: %g is negative",
+			intro, s.CVDMinDeltaE))
+	}
+
+	if err := s.Contrast.Check(); err != nil {
+		panic(intro + " RGB.Contrast: " + err.Error())
+	}
+
+	if s.MinContrast < 0 {
+		panic(fmt.Sprintf("%s RGB.MinContrast: %g is negative",
+			intro, s.MinContrast))
+	}
+
+	if len(s.AutoFamilies) > 0 {
+		if len(s.AutoCandidates) > 0 {
+			panic(intro + " RGB.AutoFamilies:" +
+				" cannot be used with the AutoCandidates")
+		}
+
+		if err := s.AutoFamilies.Check(); err != nil {
+			panic(intro + " RGB.AutoFamilies: " + err.Error())
+		}
+	}
+
+	s.CVD.register(s.Value1, s.simulateCVD)
 }
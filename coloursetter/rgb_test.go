@@ -38,6 +38,51 @@ func TestRGBCurrentValue(t *testing.T) {
 	}
 }
 
+func TestRGBCurrentValueNearest(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		s      RGB
+		v      color.RGBA //nolint:misspell
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("exact match"),
+			s:      RGB{Families: colour.Families{colour.X11Colours}},
+			v:      color.RGBA{B: 0x80, A: 0xff}, //nolint:misspell
+			expVal: "#000080 (= x11:navy)",
+		},
+		{
+			ID:     testhelper.MkID("near match"),
+			s:      RGB{Families: colour.Families{colour.X11Colours}},
+			v:      color.RGBA{R: 0x1f, G: 0x3b, B: 0x70, A: 0xff}, //nolint:misspell
+			expVal: "#1f3b70 (≈ x11:royalblue4, ΔE 4.3)",
+		},
+		{
+			ID: testhelper.MkID("near match - CIE76"),
+			s: RGB{
+				Families:  colour.Families{colour.X11Colours},
+				NearestBy: CIE76,
+			},
+			v:      color.RGBA{R: 0x1f, G: 0x3b, B: 0x70, A: 0xff}, //nolint:misspell
+			expVal: "#1f3b70 (≈ x11:dodgerblue4, ΔE 9.7)",
+		},
+		{
+			ID:     testhelper.MkID("near match - translucent, show hex"),
+			s:      RGB{ShowHex: true, ChannelOrder: ChannelOrderARGB},
+			v:      color.RGBA{R: 0x1f, G: 0x3b, B: 0x70, A: 0x80}, //nolint:misspell
+			expVal: "#801f3b70 (≈ pantone:mazarine blue, ΔE 1.7)",
+		},
+	}
+
+	for _, tc := range testCases {
+		s := tc.s
+		s.Value = &tc.v
+		s.ShowNearest = true
+		testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+			s.CurrentValue(), tc.expVal)
+	}
+}
+
 const (
 	updFlagNameRGB     = "upd-gf-RGB"
 	keepBadFlagNameRGB = "keep-bad-RGB"
@@ -307,6 +352,82 @@ func TestRGBSetter(t *testing.T) {
 				`the colour definition starts with "RGBA{"`,
 				` but has no trailing "}"`),
 		},
+		{
+			ID: testhelper.MkID("bad channel order"),
+			ExpPanic: testhelper.MkExpPanic(
+				"param-name: coloursetter.RGB Check failed:" +
+					` RGB.ChannelOrder: "GRB" is not a valid ChannelOrder`),
+			PSetter: RGB{
+				Value:        &val,
+				ChannelOrder: ChannelOrder("GRB"),
+			},
+		},
+		{
+			ID: testhelper.MkID("bad nearest formula"),
+			ExpPanic: testhelper.MkExpPanic(
+				"param-name: coloursetter.RGB Check failed:" +
+					" RGB.NearestBy: 7 is not a valid DeltaEFormula"),
+			PSetter: RGB{
+				Value:     &val,
+				NearestBy: DeltaEFormula(7),
+			},
+		},
+		{
+			ID: testhelper.MkID("goodSetter.goodval.hex.RGBA"),
+			PSetter: RGB{
+				Value: &val,
+			},
+			ParamVal: "#ff000080",
+		},
+		{
+			ID: testhelper.MkID("goodSetter.goodval.hex.ARGB"),
+			PSetter: RGB{
+				Value:        &val,
+				ChannelOrder: ChannelOrderARGB,
+				ShowHex:      true,
+			},
+			ParamVal: "#80ff0000",
+		},
+		{
+			ID: testhelper.MkID("goodSetter.goodval.int.BGR"),
+			PSetter: RGB{
+				Value:        &val,
+				ChannelOrder: ChannelOrderBGR,
+			},
+			ParamVal: "0x00ff0000",
+		},
+		{
+			ID: testhelper.MkID("goodSetter.goodval.prefix.kml"),
+			PSetter: RGB{
+				Value: &val,
+			},
+			ParamVal: "kml:7f0000ff",
+		},
+		{
+			ID: testhelper.MkID("goodSetter.goodval.X11.rgb"),
+			PSetter: RGB{
+				Value: &val,
+			},
+			ParamVal: "rgb:ffff/8080/0000",
+		},
+		{
+			ID: testhelper.MkID("goodSetter.goodval.X11.rgbi"),
+			PSetter: RGB{
+				Value: &val,
+			},
+			ParamVal: "rgbi:1.0/0.5/0.0",
+		},
+		{
+			ID: testhelper.MkID("goodSetter.badval.hex.RGB"),
+			PSetter: RGB{
+				Value:        &val,
+				ChannelOrder: ChannelOrderRGB,
+			},
+			ParamVal: "#ff000080",
+			SetWithValErr: testhelper.MkExpErr(
+				`the hex colour ("#ff000080") is badly formed:` +
+					` 8 digits found, 3 or 6 expected (order: RGB)`),
+		},
 		{
 			ID: testhelper.MkID("goodSetter.badval.famAndCol.badFam"),
 			PSetter: RGB{
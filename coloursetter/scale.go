@@ -0,0 +1,341 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// These are the limits and default values for the tint and shade scale
+// generator
+const (
+	scalePrefix   = "scale:"
+	scaleKeyIntro = "@"
+
+	MinScaleSteps  = 2
+	MaxScaleSteps  = 11
+	DfltScaleSteps = 10
+
+	// ScaleMaxL and ScaleMinL are the OKLab lightness of the lightest and
+	// darkest steps of a scale
+	ScaleMaxL = 0.97
+	ScaleMinL = 0.25
+
+	// scaleTintChroma and scaleShadeChroma give the proportion of the
+	// chroma of the base colour that is kept at the lightest and darkest
+	// steps of a scale
+	scaleTintChroma  = 0.15
+	scaleShadeChroma = 0.6
+)
+
+// scaleKeys holds the names of the steps of a scale, lightest first
+var scaleKeys = []string{
+	"50", "100", "200", "300", "400", "500",
+	"600", "700", "800", "900", "950",
+}
+
+// ColourScale is an ordered list of tints and shades derived from a single
+// base colour, lightest first. Each step has a key, as used by CSS
+// frameworks such as Tailwind: 50, 100, 200 and so on up to 900 (and 950
+// for an eleven-step scale). The base colour appears unchanged at the step
+// given by the BaseKey.
+type ColourScale struct {
+	Base    color.RGBA //nolint:misspell
+	BaseKey string
+	Keys    []string
+	Colours []color.RGBA //nolint:misspell
+}
+
+// scaleBaseIdx returns the index of the step nearest in lightness to the
+// base colour when the steps are evenly spaced
+func scaleBaseIdx(baseL float64, n int) int {
+	step := (ScaleMaxL - ScaleMinL) / float64(n-1)
+	idx := int(math.Round((ScaleMaxL - baseL) / step))
+
+	return max(0, min(n-1, idx))
+}
+
+// MakeColourScale returns a scale of n steps derived from the base colour
+// (the alpha channel of which is ignored). The base colour is pinned at the
+// step with the given key or, if the key is empty, at the step which, with
+// evenly spaced lightness, is nearest in lightness to the base colour. The
+// lighter steps are interpolated in the OKLab colour space between the
+// lightest step and the base colour and the darker steps between the base
+// colour and the darkest step, keeping the hue of the base colour. It
+// returns an error if the steps would not be successively darker.
+func MakeColourScale(base color.RGBA, n int, baseKey string) (ColourScale, error) { //nolint:misspell
+	if n < MinScaleSteps || n > MaxScaleSteps {
+		return ColourScale{},
+			fmt.Errorf("bad number of scale steps: %d (it must be from %d to %d)",
+				n, MinScaleSteps, MaxScaleSteps)
+	}
+
+	base.A = math.MaxUint8
+	baseOK := MakeOKLab(base)
+
+	cs := ColourScale{
+		Base:    base,
+		Keys:    slices.Clone(scaleKeys[:n]),
+		Colours: make([]color.RGBA, n), //nolint:misspell
+	}
+
+	b := scaleBaseIdx(baseOK.L, n)
+
+	if baseKey != "" {
+		b = slices.Index(cs.Keys, baseKey)
+		if b < 0 {
+			return ColourScale{},
+				fmt.Errorf("bad scale key %q: it must be one of %s",
+					baseKey, strings.Join(cs.Keys, ", "))
+		}
+	}
+
+	cs.BaseKey = cs.Keys[b]
+
+	baseC, hue := baseOK.Chroma(), baseOK.Hue()
+
+	for i := range n {
+		var l, chroma float64
+
+		switch {
+		case i < b:
+			t := float64(b-i) / float64(b)
+			l = baseOK.L + t*(ScaleMaxL-baseOK.L)
+			chroma = baseC * (1 - t*(1-scaleTintChroma))
+		case i > b:
+			t := float64(i-b) / float64(n-1-b)
+			l = baseOK.L + t*(ScaleMinL-baseOK.L)
+			chroma = baseC * (1 - t*(1-scaleShadeChroma))
+		default:
+			cs.Colours[i] = base
+			continue
+		}
+
+		cs.Colours[i] = MakeOKLCH(l, chroma, hue).ToRGBAInGamut()
+	}
+
+	if err := cs.checkMonotonic(); err != nil {
+		return ColourScale{}, err
+	}
+
+	return cs, nil
+}
+
+// checkMonotonic returns a non-nil error if the steps of the scale are not
+// successively darker
+func (cs ColourScale) checkMonotonic() error {
+	for i := 1; i < len(cs.Colours); i++ {
+		prevL := MakeOKLab(cs.Colours[i-1]).L
+		thisL := MakeOKLab(cs.Colours[i]).L
+
+		if thisL >= prevL {
+			return fmt.Errorf("the scale is not monotonic in lightness:"+
+				" step %s (L: %.3f) is not darker than step %s (L: %.3f)."+
+				" Pin the base colour (%s) at a different step",
+				cs.Keys[i], thisL, cs.Keys[i-1], prevL,
+				ChannelOrderRGB.Hex(cs.Base))
+		}
+	}
+
+	return nil
+}
+
+// Map returns the colours of the scale keyed by the step names
+func (cs ColourScale) Map() map[string]color.RGBA { //nolint:misspell
+	m := make(map[string]color.RGBA, len(cs.Keys)) //nolint:misspell
+	for i, k := range cs.Keys {
+		m[k] = cs.Colours[i]
+	}
+
+	return m
+}
+
+// NamedColours returns the colours of the scale, lightest first, each
+// named with its step key
+func (cs ColourScale) NamedColours() []colour.NamedColour {
+	ncs := make([]colour.NamedColour, 0, len(cs.Keys))
+	for i, k := range cs.Keys {
+		ncs = append(ncs, colour.MakeNamedColour(k, cs.Colours[i]))
+	}
+
+	return ncs
+}
+
+// String returns a description of the ColourScale
+func (cs ColourScale) String() string {
+	steps := make([]string, 0, len(cs.Keys))
+	for i, k := range cs.Keys {
+		steps = append(steps, k+"="+ChannelOrderRGB.Hex(cs.Colours[i]))
+	}
+
+	return strings.Join(steps, ", ")
+}
+
+// parseScaleParts splits the scale value (without any leading "scale:")
+// into the base colour, the number of steps and the key of the step at
+// which to pin the base colour. If the text after the last colon is not a
+// number (optionally followed by a key) the whole value is taken as the
+// colour and the number of steps is DfltScaleSteps. This allows colours
+// such as x11:navy to be given without a number of steps.
+func (p colourParser) parseScaleParts(val string,
+) (color.RGBA, int, string, error) { //nolint:misspell
+	colourStr, stepsStr, found := cutLast(val, ":")
+	nStr, key, _ := strings.Cut(stepsStr, scaleKeyIntro)
+
+	var nErr error
+
+	if found {
+		n, err := strconv.Atoi(strings.TrimSpace(nStr))
+		if err == nil {
+			base, err := p.parseRGBA(colourStr)
+			if err == nil {
+				return base, n, strings.TrimSpace(key), nil
+			}
+
+			// the number may be part of the colour (as in bgr:123456)
+			if _, wholeErr := p.parseRGBA(val); wholeErr != nil {
+				return base, n, "", err
+			}
+		}
+
+		nErr = fmt.Errorf("(if %q is meant to be the number of steps,"+
+			" it is not a number)", nStr)
+	}
+
+	base, err := p.parseRGBA(val)
+	if err != nil && nErr != nil {
+		err = fmt.Errorf("%w %w", err, nErr)
+	}
+
+	return base, DfltScaleSteps, "", err
+}
+
+// parseScale parses a scale, "COLOUR", "COLOUR:N" or "COLOUR:N@KEY" (with
+// or without a leading "scale:"), and returns the generated scale
+func (p colourParser) parseScale(s string) (ColourScale, error) {
+	val := strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToLower(val), scalePrefix) {
+		val = val[len(scalePrefix):]
+	}
+
+	base, n, key, err := p.parseScaleParts(val)
+	if err != nil {
+		return ColourScale{}, fmt.Errorf("bad scale %q: %w", s, err)
+	}
+
+	cs, err := MakeColourScale(base, n, key)
+	if err != nil {
+		return ColourScale{}, fmt.Errorf("bad scale %q: %w", s, err)
+	}
+
+	return cs, nil
+}
+
+// scaleAllowedValues describes the scale notation
+func scaleAllowedValues() string {
+	return scalePrefix + "colour:N to generate a scale of N (from " +
+		strconv.Itoa(MinScaleSteps) + " to " + strconv.Itoa(MaxScaleSteps) +
+		") tints and shades of the colour, lightest first." +
+		" The :N may be omitted, the scale then has " +
+		strconv.Itoa(DfltScaleSteps) + " steps." +
+		" The steps are named " + strings.Join(scaleKeys, ", ") +
+		" (as many as are needed) and the colour itself is placed" +
+		" at the step nearest to it in lightness" +
+		" unless the step is given after the number" +
+		" (for instance, " + scalePrefix + "#1e66f5:" +
+		strconv.Itoa(DfltScaleSteps) + scaleKeyIntro + "500)"
+}
+
+// Scale is used to set a ColourScale. The value is a base colour and an
+// optional number of steps (DfltScaleSteps if not given), optionally
+// preceded by scale: and optionally followed by the key of the step at
+// which to pin the base colour (for instance, scale:#1e66f5:10@500).
+//
+//nolint:misspell
+type Scale struct {
+	psetter.ValueReqMandatory
+
+	Value    *ColourScale
+	Families colour.Families
+
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+	// CVD, if set, has the colours transformed by the simulation of a colour
+	// vision deficiency, if any, once all the parameters have been parsed
+	// (see CVDScope).
+	CVD *CVDScope
+}
+
+// parser returns the colourParser for this setter
+func (s Scale) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) generates the
+// scale and, if it is valid, sets the Value.
+func (s Scale) SetWithVal(_ string, paramVal string) error {
+	cs, err := s.parser().parseScale(paramVal)
+	if err != nil {
+		return err
+	}
+
+	*s.Value = cs
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Scale) AllowedValues() string {
+	return "a tint and shade scale given as " + scaleAllowedValues() +
+		". The leading " + scalePrefix + " may be omitted" +
+		"\n\n" +
+		"The colour is given as follows. " + s.parser().allowedValues()
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Scale) ValDescribe() string {
+	return "colour[:N]"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Scale) CurrentValue() string {
+	return s.Value.String()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the ChannelOrder is
+// invalid. If the CVD is set the Value is registered with it.
+func (s Scale) CheckSetter(name string) {
+	intro := name + ": coloursetter.Scale Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " Scale.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " Scale.Families: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " Scale.ChannelOrder: " + err.Error())
+	}
+
+	s.CVD.register(s.Value, func(t CVDType) {
+		s.Value.Base = SimulateCVD(s.Value.Base, t)
+		for i, c := range s.Value.Colours {
+			s.Value.Colours[i] = SimulateCVD(c, t)
+		}
+	})
+}
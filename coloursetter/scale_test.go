@@ -0,0 +1,159 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestScaleSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v          string
+		expBaseKey string
+		expVal     string
+	}{
+		{
+			ID:         testhelper.MkID("base step chosen by lightness"),
+			v:          "scale:#1e66f5:10",
+			expBaseKey: "500",
+			expVal: "50=#f0f5fe, 100=#c7dbfe, 200=#a0c0fa, 300=#77a4f9," +
+				" 400=#4986fc, 500=#1e66f5, 600=#1151ce, 700=#053ca8," +
+				" 800=#042c7c, 900=#021b55",
+		},
+		{
+			ID:         testhelper.MkID("base step given, no prefix"),
+			v:          "red:5@50",
+			expBaseKey: "50",
+			expVal: "50=#ff0000, 100=#ca170f, 200=#99150d," +
+				" 300=#6e0b06, 400=#470100",
+		},
+		{
+			ID:         testhelper.MkID("grey"),
+			v:          "scale:grey:3",
+			expBaseKey: "100",
+			expVal:     "50=#f5f5f5, 100=#808080, 200=#222222",
+		},
+		{
+			ID: testhelper.MkID("not monotonic"),
+			ExpErr: testhelper.MkExpErr(
+				`bad scale "scale:white:10@500":`,
+				"the scale is not monotonic in lightness:",
+				"step 100 (L: 0.976) is not darker than step 50 (L: 0.970)"),
+			v: "scale:white:10@500",
+		},
+		{
+			ID: testhelper.MkID("too few steps"),
+			ExpErr: testhelper.MkExpErr(`bad scale "#1e66f5:1":` +
+				" bad number of scale steps: 1 (it must be from 2 to 11)"),
+			v: "#1e66f5:1",
+		},
+		{
+			ID: testhelper.MkID("bad key"),
+			ExpErr: testhelper.MkExpErr(`bad scale key "950":` +
+				" it must be one of 50, 100, 200, 300, 400," +
+				" 500, 600, 700, 800, 900"),
+			v: "#1e66f5:10@950",
+		},
+		{
+			ID:         testhelper.MkID("no steps"),
+			v:          "scale:#1e66f5",
+			expBaseKey: "500",
+			expVal: "50=#f0f5fe, 100=#c7dbfe, 200=#a0c0fa, 300=#77a4f9," +
+				" 400=#4986fc, 500=#1e66f5, 600=#1151ce, 700=#053ca8," +
+				" 800=#042c7c, 900=#021b55",
+		},
+		{
+			ID:         testhelper.MkID("no steps, family colour"),
+			v:          "scale:x11:navy",
+			expBaseKey: "900",
+			expVal: "50=#f0f5ff, 100=#ccdcfb, 200=#aec3ed, 300=#90aade," +
+				" 400=#7391cf, 500=#5779c0, 600=#3c60b1, 700=#2247a1," +
+				" 800=#082b91, 900=#000080",
+		},
+		{
+			ID:         testhelper.MkID("family colour and steps"),
+			v:          "scale:x11:navy:3",
+			expBaseKey: "200",
+			expVal:     "50=#f0f5ff, 100=#6585c8, 200=#000080",
+		},
+		{
+			ID:         testhelper.MkID("digits in the colour"),
+			v:          "bgr:123456",
+			expBaseKey: "800",
+			expVal: "50=#faf4ee, 100=#e5dad1, 200=#cfc0b3, 300=#baa797," +
+				" 400=#a68f7b, 500=#917761, 600=#7d6047, 700=#694a2d," +
+				" 800=#563412, 900=#2f1d0b",
+		},
+		{
+			ID: testhelper.MkID("bad steps"),
+			ExpErr: testhelper.MkExpErr(`bad scale "navy:ten":`,
+				`(if "ten" is meant to be the number of steps,`+
+					" it is not a number)"),
+			v: "navy:ten",
+		},
+		{
+			ID:     testhelper.MkID("bad colour"),
+			ExpErr: testhelper.MkExpErr(`bad scale "nonesuch:10":`),
+			v:      "nonesuch:10",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v ColourScale
+
+		err := Scale{Value: &v}.SetWithVal("", tc.v)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "base key",
+				v.BaseKey, tc.expBaseKey)
+			testhelper.DiffString(t, tc.IDStr(), "value",
+				v.String(), tc.expVal)
+		}
+	}
+}
+
+func TestColourScale(t *testing.T) {
+	navy := color.RGBA{B: 0x80, A: 0xff} //nolint:misspell
+
+	for n := MinScaleSteps; n <= MaxScaleSteps; n++ {
+		cs, err := MakeColourScale(navy, n, "")
+		if err != nil {
+			t.Errorf("%d steps: unexpected error: %s", n, err)
+			continue
+		}
+
+		m := cs.Map()
+		if len(m) != n || len(cs.NamedColours()) != n {
+			t.Errorf("%d steps: the map has %d entries", n, len(m))
+		}
+
+		if m[cs.BaseKey] != navy {
+			t.Errorf("%d steps: the base colour is not at step %s",
+				n, cs.BaseKey)
+		}
+	}
+
+	cs, err := MakeColourScale(navy, MaxScaleSteps, "")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+
+	if k := cs.Keys[len(cs.Keys)-1]; k != "950" {
+		t.Errorf("the last key of the longest scale should be 950, not %s", k)
+	}
+}
+
+func TestPaletteScale(t *testing.T) {
+	var v color.Palette //nolint:misspell
+
+	err := Palette{Value: &v}.SetWithVal("", "scale:teal:10,scale:#f5a97f:5")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+
+	if len(v) != 15 {
+		t.Errorf("the palette should have 15 colours, not %d", len(v))
+	}
+}
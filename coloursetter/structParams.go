@@ -0,0 +1,299 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+)
+
+// These are the struct tags used by StructParams
+const (
+	// TagParamName gives the name of the parameter. Only fields having
+	// this tag are registered; the name "-" also causes the field to be
+	// ignored.
+	TagParamName = "param"
+	// TagDesc gives the description of the parameter. It must be present.
+	TagDesc = "desc"
+	// TagAltNames gives a comma-separated list of alternative names for
+	// the parameter.
+	TagAltNames = "alt"
+	// TagFamilies gives a comma-separated list of the colour families (or
+	// their aliases) used to interpret colour names.
+	TagFamilies = "families"
+	// TagColourOpts gives a comma-separated list of options and
+	// constraints for the setter (see StructParams).
+	TagColourOpts = "colour"
+)
+
+// These are the options which may be given in the TagColourOpts tag
+const (
+	structOptHex       = "hex"
+	structOptNearest   = "nearest"
+	structOptOrder     = "order"
+	structOptMaxLen    = "maxlen"
+	structOptAllowDups = "allow-duplicates"
+	structOptCVDMinDE  = "cvd-min-de"
+)
+
+// structSetterOpts records the options given in the struct tags of a field
+type structSetterOpts struct {
+	families    colour.Families
+	showHex     bool
+	showNearest bool
+	order       ChannelOrder
+	maxLen      int
+	allowDups   bool
+	cvdMinDE    float64
+
+	given []string
+}
+
+// StructParams adds parameters to a param set for the colour fields of a
+// struct. Each exported field having a TagParamName tag is registered
+// using the setter for its type:
+//
+//	color.RGBA                RGB
+//	colour.NamedColour        NamedColour
+//	colour.Families           Families
+//	[]color.RGBA              RGBList
+//	[]colour.NamedColour      NamedColourList
+//	color.Palette             Palette (also []color.Color)
+//	ColourScale               Scale
+//	map[string]color.RGBA     RGBMap
+//
+// Any other type causes Add to panic.
+//
+// The TagDesc tag gives the parameter description and the TagFamilies tag
+// gives the colour families. The TagColourOpts tag gives a list of
+// options and constraints, each of which is only allowed for the setters
+// having the corresponding field:
+//
+//	hex                 ShowHex (RGB, NamedColour, NamedColourList)
+//	nearest             ShowNearest (RGB)
+//	order=CHANNELS      ChannelOrder (all but Families)
+//	maxlen=N            MaxLen (RGBList, Palette)
+//	allow-duplicates    AllowDuplicates (RGBList, Palette)
+//	cvd-min-de=N        CVDMinDeltaE (RGBList, NamedColourList, Palette,
+//	                    RGBMap)
+//
+// For instance:
+//
+//	type Config struct {
+//		FG     color.RGBA `param:"fg" desc:"the text colour" colour:"hex"`
+//		Accent color.RGBA `param:"accent" desc:"the accent colour" families:"x11"`
+//	}
+//
+// The Scope and Refs, if set, are given to the RGB and NamedColour setters
+// and the Opts are passed to every parameter added.
+type StructParams struct {
+	Scope *FamilyScope
+	Refs  *ColourRefs
+	Opts  []param.ByNameOptFunc
+}
+
+// AddStructParams adds parameters to the param set for the colour fields of
+// the struct pointed to by cfg (see StructParams).
+func AddStructParams(ps *param.PSet, cfg any) {
+	StructParams{}.Add(ps, cfg)
+}
+
+// Add adds parameters to the param set for the colour fields of the struct
+// pointed to by cfg. It panics if cfg is not a non-nil pointer to a struct
+// or if any tagged field is unexported, has no description, has an
+// unsupported type or has bad tag values. These are errors in the program
+// rather than in the parameters and so, as with CheckSetter, they are
+// reported when the parameters are set up.
+func (sp StructParams) Add(ps *param.PSet, cfg any) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() ||
+		v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf(
+			"coloursetter.StructParams.Add: %T is not a pointer to a struct",
+			cfg))
+	}
+
+	sv := v.Elem()
+	st := sv.Type()
+
+	for i := range st.NumField() {
+		f := st.Field(i)
+
+		name, ok := f.Tag.Lookup(TagParamName)
+		if !ok || name == "-" {
+			continue
+		}
+
+		intro := "coloursetter.StructParams.Add: " +
+			st.Name() + "." + f.Name + ":"
+
+		if !f.IsExported() {
+			panic(intro + " the field is not exported")
+		}
+
+		desc := f.Tag.Get(TagDesc)
+		if desc == "" {
+			panic(intro + " there is no " + TagDesc + " tag")
+		}
+
+		setter, err := sp.makeSetter(f, sv.Field(i).Addr())
+		if err != nil {
+			panic(intro + " " + err.Error())
+		}
+
+		opts := sp.Opts
+		if alt := f.Tag.Get(TagAltNames); alt != "" {
+			opts = append(slices.Clone(opts),
+				param.AltNames(strings.Split(alt, ",")...))
+		}
+
+		ps.Add(name, setter, desc, opts...)
+	}
+}
+
+// makeSetter returns the setter for the field. The addr is the address of
+// the field.
+func (sp StructParams) makeSetter(f reflect.StructField, addr reflect.Value,
+) (param.Setter, error) {
+	o, err := parseStructSetterOpts(f.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := addr.Interface().(type) {
+	case *color.RGBA: //nolint:misspell
+		return RGB{
+			Value: v, Families: o.families, ChannelOrder: o.order,
+			ShowHex: o.showHex, ShowNearest: o.showNearest,
+			Scope: sp.Scope, Refs: sp.Refs,
+		}, o.check("RGB", TagFamilies, structOptHex, structOptNearest,
+			structOptOrder)
+	case *colour.NamedColour:
+		return NamedColour{
+			Value: v, Families: o.families, ChannelOrder: o.order,
+			ShowHex: o.showHex,
+			Scope:   sp.Scope, Refs: sp.Refs,
+		}, o.check("NamedColour", TagFamilies, structOptHex, structOptOrder)
+	case *colour.Families:
+		return Families{Value: v}, o.check("Families")
+	case *[]color.RGBA: //nolint:misspell
+		return RGBList{
+			Value: v, Families: o.families, ChannelOrder: o.order,
+			MaxLen: o.maxLen, AllowDuplicates: o.allowDups,
+			CVDMinDeltaE: o.cvdMinDE,
+		}, o.check("RGBList", TagFamilies, structOptOrder, structOptMaxLen,
+			structOptAllowDups, structOptCVDMinDE)
+	case *[]colour.NamedColour:
+		return NamedColourList{
+			Value: v, Families: o.families, ChannelOrder: o.order,
+			ShowHex: o.showHex, CVDMinDeltaE: o.cvdMinDE,
+		}, o.check("NamedColourList", TagFamilies, structOptHex,
+			structOptOrder, structOptCVDMinDE)
+	case *color.Palette, *[]color.Color: //nolint:misspell
+		pv := addr.Convert(reflect.TypeFor[*color.Palette]()) //nolint:misspell
+		p := pv.Interface().(*color.Palette)                  //nolint:misspell,forcetypeassert
+
+		return Palette{
+			Value: p, Families: o.families, ChannelOrder: o.order,
+			MaxLen: o.maxLen, AllowDuplicates: o.allowDups,
+			CVDMinDeltaE: o.cvdMinDE,
+		}, o.check("Palette", TagFamilies, structOptOrder, structOptMaxLen,
+			structOptAllowDups, structOptCVDMinDE)
+	case *ColourScale:
+		return Scale{
+			Value: v, Families: o.families, ChannelOrder: o.order,
+		}, o.check("Scale", TagFamilies, structOptOrder)
+	case *map[string]color.RGBA: //nolint:misspell
+		return RGBMap{
+			Value: v, Families: o.families, ChannelOrder: o.order,
+			CVDMinDeltaE: o.cvdMinDE,
+		}, o.check("RGBMap", TagFamilies, structOptOrder, structOptCVDMinDE)
+	}
+
+	return nil, fmt.Errorf("unsupported field type: %s", f.Type)
+}
+
+// parseStructSetterOpts parses the TagFamilies and TagColourOpts tags
+func parseStructSetterOpts(tag reflect.StructTag) (structSetterOpts, error) {
+	o := structSetterOpts{}
+
+	if fams, ok := tag.Lookup(TagFamilies); ok {
+		o.given = append(o.given, TagFamilies)
+
+		if err := (Families{Value: &o.families}).SetWithVal("", fams); err != nil {
+			return o, fmt.Errorf("bad %s tag: %w", TagFamilies, err)
+		}
+	}
+
+	optStr, ok := tag.Lookup(TagColourOpts)
+	if !ok {
+		return o, nil
+	}
+
+	for _, opt := range strings.Split(optStr, ",") {
+		name, val, hasVal := strings.Cut(strings.TrimSpace(opt), "=")
+		o.given = append(o.given, name)
+
+		var err error
+
+		switch name {
+		case structOptHex:
+			o.showHex = true
+		case structOptNearest:
+			o.showNearest = true
+		case structOptAllowDups:
+			o.allowDups = true
+		case structOptOrder:
+			o.order = ChannelOrder(strings.ToUpper(val))
+			err = o.order.Check()
+		case structOptMaxLen:
+			o.maxLen, err = strconv.Atoi(val)
+		case structOptCVDMinDE:
+			o.cvdMinDE, err = strconv.ParseFloat(val, 64)
+		default:
+			return o, fmt.Errorf("bad %s tag: unknown option %q",
+				TagColourOpts, name)
+		}
+
+		if err == nil && hasVal != structOptTakesVal(name) {
+			if hasVal {
+				err = errors.New("no value is allowed")
+			} else {
+				err = errors.New("a value is needed")
+			}
+		}
+
+		if err != nil {
+			return o, fmt.Errorf("bad %s tag: option %q: %w",
+				TagColourOpts, opt, err)
+		}
+	}
+
+	return o, nil
+}
+
+// structOptTakesVal returns true if the option takes a value
+func structOptTakesVal(name string) bool {
+	return name == structOptOrder ||
+		name == structOptMaxLen ||
+		name == structOptCVDMinDE
+}
+
+// check returns a non-nil error if any option has been given which is not
+// in the allowed list for the setter
+func (o structSetterOpts) check(setterName string, allowed ...string) error {
+	for _, name := range o.given {
+		if !slices.Contains(allowed, name) {
+			return fmt.Errorf("the %q option cannot be used with"+
+				" a field set by a %s setter", name, setterName)
+		}
+	}
+
+	return nil
+}
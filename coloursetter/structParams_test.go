@@ -0,0 +1,194 @@
+package coloursetter
+
+import (
+	"errors"
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// structParamsCfg is a config struct used to test StructParams
+//
+//nolint:misspell
+type structParamsCfg struct {
+	FG      color.RGBA            `param:"fg" desc:"the text colour" colour:"hex,order=bgr"`
+	Gray    color.RGBA            `param:"gray" desc:"a gray" families:"x11" alt:"grey"`
+	Name    colour.NamedColour    `param:"name" desc:"a named colour" colour:"hex"`
+	Fams    colour.Families       `param:"families" desc:"the colour families"`
+	List    []color.RGBA          `param:"list" desc:"a list of colours" colour:"allow-duplicates"`
+	Names   []colour.NamedColour  `param:"names" desc:"some named colours" families:"x11"`
+	Pal     color.Palette         `param:"palette" desc:"a palette" colour:"maxlen=2"`
+	Colours []color.Color         `param:"colours" desc:"some colours"`
+	Scale   ColourScale           `param:"scale" desc:"a colour scale"`
+	Levels  map[string]color.RGBA `param:"levels" desc:"the level colours"`
+	Ignored color.RGBA            `param:"-"`
+	Count   int
+}
+
+func TestStructParams(t *testing.T) {
+	cfg := structParamsCfg{}
+	ps := param.NewSet(quietHelper{})
+
+	AddStructParams(ps, &cfg)
+
+	ps.Parse([]string{
+		"-fg", "#0000ff",
+		"-grey", "gray",
+		"-name", "navy",
+		"-families", "web",
+		"-list", "red,#f00,blue",
+		"-names", "navy,gray",
+		"-palette", "red,green",
+		"-colours", "blue",
+		"-scale", "navy:3",
+		"-levels", "error=red",
+	})
+
+	errs := []error{}
+	for name, paramErrs := range ps.Errors() {
+		for _, err := range paramErrs {
+			errs = append(errs, errors.New(name+": "+err.Error()))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal("unexpected errors: ", err)
+	}
+
+	for _, name := range []string{"ignored", "count"} {
+		if _, err := ps.GetParamByName(name); err == nil {
+			t.Errorf("the %q parameter should not have been added", name)
+		}
+	}
+
+	testhelper.DiffString(t, "struct params", "fg",
+		ChannelOrderRGBA.Hex(cfg.FG), "#ff0000ff")
+	testhelper.DiffString(t, "struct params", "gray",
+		ChannelOrderRGBA.Hex(cfg.Gray), "#bebebeff")
+	testhelper.DiffString(t, "struct params", "name",
+		cfg.Name.Name(), "navy")
+	testhelper.DiffInt(t, "struct params", "families", len(cfg.Fams), 1)
+	testhelper.DiffInt(t, "struct params", "list", len(cfg.List), 2)
+
+	if !testhelper.DiffInt(t, "struct params", "names", len(cfg.Names), 2) {
+		testhelper.DiffString(t, "struct params", "names",
+			cfg.Names[1].Name()+" "+ChannelOrderRGBA.Hex(cfg.Names[1].Colour()),
+			"gray #bebebeff")
+	}
+
+	testhelper.DiffInt(t, "struct params", "palette", len(cfg.Pal), 2)
+	testhelper.DiffInt(t, "struct params", "colours", len(cfg.Colours), 1)
+	testhelper.DiffInt(t, "struct params", "scale", len(cfg.Scale.Colours), 3)
+	testhelper.DiffString(t, "struct params", "levels",
+		ChannelOrderRGBA.Hex(cfg.Levels["error"]), "#ff0000ff")
+
+	ps = param.NewSet(quietHelper{})
+	AddStructParams(ps, &structParamsCfg{})
+	ps.Parse([]string{"-palette", "red,green,blue"})
+
+	if len(ps.Errors()["palette"]) == 0 {
+		t.Error("the palette maxlen constraint should have been applied")
+	}
+}
+
+func TestStructParamsRefs(t *testing.T) {
+	cfg := structParamsCfg{}
+	ps := param.NewSet(quietHelper{})
+	refs := NewColourRefs()
+
+	StructParams{Refs: refs}.Add(ps, &cfg)
+	refs.AddFinalCheck(ps)
+
+	ps.Parse([]string{"-gray", "@fg", "-name", "same-as:fg", "-fg", "navy"})
+
+	for name, paramErrs := range ps.Errors() {
+		for _, err := range paramErrs {
+			t.Errorf("unexpected error: %s: %s", name, err)
+		}
+	}
+
+	testhelper.DiffString(t, "struct params refs", "gray",
+		ChannelOrderRGBA.Hex(cfg.Gray), "#000080ff")
+	testhelper.DiffString(t, "struct params refs", "name",
+		ChannelOrderRGBA.Hex(cfg.Name.Colour()), "#000080ff")
+}
+
+func TestStructParamsPanic(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		cfg any
+	}{
+		{
+			ID: testhelper.MkID("not a pointer"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.StructParams.Add:" +
+					" coloursetter.structParamsCfg is not a pointer to a struct"),
+			cfg: structParamsCfg{},
+		},
+		{
+			ID: testhelper.MkID("unsupported type"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.StructParams.Add: .C:" +
+					" unsupported field type: int"),
+			cfg: &struct {
+				C int `param:"c" desc:"a count"`
+			}{},
+		},
+		{
+			ID: testhelper.MkID("slice of NRGBA"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.StructParams.Add: .C:" +
+					" unsupported field type: []color.NRGBA"),
+			cfg: &struct {
+				C []color.NRGBA `param:"c" desc:"some colours"` //nolint:misspell
+			}{},
+		},
+		{
+			ID: testhelper.MkID("no description"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.StructParams.Add: .C: there is no desc tag"),
+			cfg: &struct {
+				C color.RGBA `param:"c"` //nolint:misspell
+			}{},
+		},
+		{
+			ID: testhelper.MkID("bad families"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.StructParams.Add: .C:" +
+					` bad families tag: bad family name "nosuchfamily"`),
+			cfg: &struct {
+				C color.RGBA `param:"c" desc:"c" families:"nosuchfamily"` //nolint:misspell
+			}{},
+		},
+		{
+			ID: testhelper.MkID("option not allowed"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.StructParams.Add: .C:" +
+					` the "maxlen" option cannot be used with` +
+					" a field set by a RGB setter"),
+			cfg: &struct {
+				C color.RGBA `param:"c" desc:"c" colour:"maxlen=3"` //nolint:misspell
+			}{},
+		},
+		{
+			ID: testhelper.MkID("option missing a value"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.StructParams.Add: .C:" +
+					` bad colour tag: option "order": a value is needed`),
+			cfg: &struct {
+				C color.RGBA `param:"c" desc:"c" colour:"order"` //nolint:misspell
+			}{},
+		},
+	}
+
+	for _, tc := range testCases {
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			AddStructParams(param.NewSet(quietHelper{}), tc.cfg)
+		})
+		testhelper.CheckExpPanic(t, panicked, panicVal, tc)
+	}
+}
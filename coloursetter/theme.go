@@ -0,0 +1,230 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"slices"
+
+	"github.com/nickwells/param.mod/v7/param"
+)
+
+// themeRole is a named colour in a Theme
+type themeRole struct {
+	name string
+	c    *color.RGBA //nolint:misspell
+}
+
+// themeRule is a check applied to the colours of a Theme. It returns an
+// error for each violation of the rule.
+type themeRule func() []error
+
+// Theme checks the colours set by several parameters against each
+// other. Individual setters only see their own value so, for instance, a
+// foreground colour and a background colour set by separate parameters
+// cannot be checked for contrast by either setter. Instead each colour is
+// registered with the Theme as a named role, bound to the same
+// *color.RGBA as the setter, and rules are declared between the roles. The
+// rules are checked after all the parameters have been parsed (see
+// AddFinalCheck) and every violation is reported.
+//
+// The methods which register roles and declare rules panic if they are
+// given bad values (such as an unknown role name) as these are errors in
+// the program rather than in the parameters.
+type Theme struct {
+	roles []themeRole
+	rules []themeRule
+}
+
+// NewTheme returns a new, empty, Theme
+func NewTheme() *Theme {
+	return &Theme{}
+}
+
+// role returns the role with the given name
+func (t *Theme) role(name string) (themeRole, bool) {
+	idx := slices.IndexFunc(t.roles,
+		func(r themeRole) bool { return r.name == name })
+	if idx < 0 {
+		return themeRole{}, false
+	}
+
+	return t.roles[idx], true
+}
+
+// mustRoles returns the roles with the given names, it panics if any of
+// the names is not a registered role
+func (t *Theme) mustRoles(caller string, names ...string) []themeRole {
+	roles := make([]themeRole, 0, len(names))
+
+	for _, name := range names {
+		r, ok := t.role(name)
+		if !ok {
+			panic(fmt.Sprintf("coloursetter.Theme.%s: unknown role: %q",
+				caller, name))
+		}
+
+		roles = append(roles, r)
+	}
+
+	return roles
+}
+
+// AddRole registers the colour with the Theme under the given name. The
+// colour should be the Value of the setter for the parameter giving the
+// colour of the role. It panics if the name is empty or already in use or
+// if the colour is nil.
+func (t *Theme) AddRole(name string, c *color.RGBA) *Theme { //nolint:misspell
+	if name == "" {
+		panic("coloursetter.Theme.AddRole: the role name is empty")
+	}
+
+	if _, ok := t.role(name); ok {
+		panic(fmt.Sprintf(
+			"coloursetter.Theme.AddRole: the role %q already exists", name))
+	}
+
+	if c == nil {
+		panic(fmt.Sprintf(
+			"coloursetter.Theme.AddRole: the colour for role %q is nil", name))
+	}
+
+	t.roles = append(t.roles, themeRole{name: name, c: c})
+
+	return t
+}
+
+// Roles returns the names of the roles in the order they were added
+func (t *Theme) Roles() []string {
+	names := make([]string, 0, len(t.roles))
+	for _, r := range t.roles {
+		names = append(names, r.name)
+	}
+
+	return names
+}
+
+// RequireContrast adds a rule that the contrast, measured using the
+// metric, between the text role and the background role is at least the
+// minimum. It panics if either role is unknown or if the metric or the
+// minimum is invalid.
+func (t *Theme) RequireContrast(text, bg string,
+	m ContrastMetric, minContrast float64,
+) *Theme {
+	roles := t.mustRoles("RequireContrast", text, bg)
+
+	if err := m.Check(); err != nil {
+		panic("coloursetter.Theme.RequireContrast: " + err.Error())
+	}
+
+	if minContrast <= 0 {
+		panic(fmt.Sprintf("coloursetter.Theme.RequireContrast:"+
+			" the minimum contrast (%g) must be greater than zero",
+			minContrast))
+	}
+
+	t.rules = append(t.rules, func() []error {
+		err := m.checkMin(roles[0].name, roles[1].name,
+			*roles[0].c, *roles[1].c, minContrast)
+		if err != nil {
+			return []error{err}
+		}
+
+		return nil
+	})
+
+	return t
+}
+
+// RequireDistinct adds a rule that each pair of the named roles differ by
+// at least the minimum (CIEDE2000) Delta E. It panics if fewer than two
+// roles are given, if any role is unknown or if the minimum is not greater
+// than zero.
+func (t *Theme) RequireDistinct(minDeltaE float64, names ...string) *Theme {
+	roles := t.mustDistinctRoles("RequireDistinct", minDeltaE, names)
+
+	t.rules = append(t.rules, func() []error {
+		errs := []error{}
+
+		for i, r1 := range roles {
+			for _, r2 := range roles[i+1:] {
+				if dist := DeltaE2000(*r1.c, *r2.c); dist < minDeltaE {
+					errs = append(errs,
+						fmt.Errorf("the colours %q and %q are too similar:"+
+							" ΔE %.1f (minimum: %g)",
+							r1.name, r2.name, dist, minDeltaE))
+				}
+			}
+		}
+
+		return errs
+	})
+
+	return t
+}
+
+// RequireCVDDistinct adds a rule that each pair of the named roles, as
+// seen by someone with protanopia, deuteranopia or tritanopia, differ by at
+// least the minimum (CIEDE2000) Delta E. Roles having the same colour are
+// not reported. It panics if fewer than two roles are given, if any role
+// is unknown or if the minimum is not greater than zero.
+func (t *Theme) RequireCVDDistinct(minDeltaE float64, names ...string) *Theme {
+	roles := t.mustDistinctRoles("RequireCVDDistinct", minDeltaE, names)
+
+	t.rules = append(t.rules, func() []error {
+		colours := make([]cvdColour, 0, len(roles))
+		for _, r := range roles {
+			colours = append(colours, cvdColour{name: r.name, c: *r.c})
+		}
+
+		if err := checkCVDDistinct(colours, minDeltaE); err != nil {
+			return []error{err}
+		}
+
+		return nil
+	})
+
+	return t
+}
+
+// mustDistinctRoles checks the arguments to the distinctness rules and
+// returns the named roles. It panics if the arguments are invalid.
+func (t *Theme) mustDistinctRoles(caller string,
+	minDeltaE float64, names []string,
+) []themeRole {
+	if len(names) < 2 { //nolint:mnd
+		panic(fmt.Sprintf("coloursetter.Theme.%s:"+
+			" at least 2 roles are needed, %d given", caller, len(names)))
+	}
+
+	if minDeltaE <= 0 {
+		panic(fmt.Sprintf("coloursetter.Theme.%s:"+
+			" the minimum Delta E (%g) must be greater than zero",
+			caller, minDeltaE))
+	}
+
+	return t.mustRoles(caller, names...)
+}
+
+// Check applies all the rules to the current colours of the roles and
+// returns an error reporting every violation, or nil if there are none.
+func (t *Theme) Check() error {
+	errs := []error{}
+
+	for _, rule := range t.rules {
+		errs = append(errs, rule()...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("bad colour theme: %w", errors.Join(errs...))
+}
+
+// AddFinalCheck adds the Theme's Check to the final checks of the param
+// set so that the rules are checked once all the parameters have been
+// parsed.
+func (t *Theme) AddFinalCheck(ps *param.PSet) {
+	ps.AddFinalCheck(t.Check)
+}
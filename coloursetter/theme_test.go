@@ -0,0 +1,181 @@
+package coloursetter
+
+import (
+	"errors"
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// quietHelper is a param.Helper which neither adds parameters nor reports
+// errors; the errors are left in the PSet to be checked
+type quietHelper struct{}
+
+func (quietHelper) AddParams(_ *param.PSet)         {}
+func (quietHelper) ProcessArgs(_ *param.PSet)       {}
+func (quietHelper) ErrorHandler(_ *param.PSet)      {}
+func (quietHelper) Help(_ *param.PSet, _ ...string) {}
+
+func TestThemeFinalCheck(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		args []string
+	}{
+		{
+			ID: testhelper.MkID("all good"),
+			args: []string{
+				"-fg", "black", "-bg", "white",
+				"-ok", "green", "-warn", "orange", "-err", "red",
+			},
+		},
+		{
+			ID: testhelper.MkID("defaults"),
+		},
+		{
+			ID: testhelper.MkID("every violation reported"),
+			ExpErr: testhelper.MkExpErr(
+				"bad colour theme:",
+				`the contrast between "fg" and "bg" is too low:`+
+					" WCAG2 4.48 (minimum: 4.5)",
+				`the colours "ok" and "warn" are too similar:`,
+				`the colours "ok" and "err" are too similar:`,
+				`the colours "warn" and "err" are too similar:`),
+			args: []string{
+				"-fg", "#777", "-bg", "white",
+				"-ok", "red", "-warn", "#fe0000", "-err", "#ff0101",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		fg := color.RGBA{A: 0xff}                            //nolint:misspell
+		bg := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff} //nolint:misspell
+		okC := color.RGBA{G: 0x80, A: 0xff}                  //nolint:misspell
+		warnC := color.RGBA{R: 0xff, G: 0xa5, A: 0xff}       //nolint:misspell
+		errC := color.RGBA{R: 0xff, A: 0xff}                 //nolint:misspell
+
+		ps := param.NewSet(quietHelper{})
+		ps.Add("fg", RGB{Value: &fg}, "the text colour")
+		ps.Add("bg", RGB{Value: &bg}, "the background colour")
+		ps.Add("ok", RGB{Value: &okC}, "the success colour")
+		ps.Add("warn", RGB{Value: &warnC}, "the warning colour")
+		ps.Add("err", RGB{Value: &errC}, "the error colour")
+
+		NewTheme().
+			AddRole("fg", &fg).
+			AddRole("bg", &bg).
+			AddRole("ok", &okC).
+			AddRole("warn", &warnC).
+			AddRole("err", &errC).
+			RequireContrast("fg", "bg", WCAG2, 4.5).
+			RequireDistinct(10, "ok", "warn", "err").
+			AddFinalCheck(ps)
+
+		ps.Parse(tc.args)
+
+		err := errors.Join(ps.Errors()["Final Checks"]...)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+func TestThemeCheck(t *testing.T) {
+	fg := color.RGBA{R: 0x88, G: 0x88, B: 0x88, A: 0xff}   //nolint:misspell
+	bg := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}   //nolint:misspell
+	link := color.RGBA{R: 0x88, G: 0x88, B: 0x89, A: 0xff} //nolint:misspell
+
+	theme := NewTheme().
+		AddRole("fg", &fg).
+		AddRole("bg", &bg).
+		AddRole("link", &link).
+		RequireContrast("fg", "bg", APCA, 75).
+		RequireCVDDistinct(5, "fg", "link")
+
+	testhelper.DiffStringSlice(t, "theme", "roles",
+		theme.Roles(), []string{"fg", "bg", "link"})
+
+	tc := struct {
+		testhelper.ID
+		testhelper.ExpErr
+	}{
+		ID: testhelper.MkID("APCA contrast and CVD distinctness"),
+		ExpErr: testhelper.MkExpErr(
+			`the contrast between "fg" and "bg" is too low:`+
+				" APCA 63.06 (minimum: 75)",
+			`the colours "fg" and "link" are hard to tell apart`),
+	}
+
+	testhelper.CheckExpErr(t, theme.Check(), tc)
+
+	fg = color.RGBA{A: 0xff}                              //nolint:misspell
+	link = color.RGBA{R: 0x80, G: 0x80, B: 0xff, A: 0xff} //nolint:misspell
+
+	if err := theme.Check(); err != nil {
+		t.Error("unexpected error after the colours were changed: ", err)
+	}
+}
+
+func TestThemePanics(t *testing.T) {
+	c := color.RGBA{} //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		f func(th *Theme)
+	}{
+		{
+			ID: testhelper.MkID("empty name"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.Theme.AddRole: the role name is empty"),
+			f: func(th *Theme) { th.AddRole("", &c) },
+		},
+		{
+			ID: testhelper.MkID("duplicate name"),
+			ExpPanic: testhelper.MkExpPanic(
+				`coloursetter.Theme.AddRole: the role "fg" already exists`),
+			f: func(th *Theme) { th.AddRole("fg", &c) },
+		},
+		{
+			ID: testhelper.MkID("nil colour"),
+			ExpPanic: testhelper.MkExpPanic(
+				`coloursetter.Theme.AddRole: the colour for role "x" is nil`),
+			f: func(th *Theme) { th.AddRole("x", nil) },
+		},
+		{
+			ID: testhelper.MkID("unknown role"),
+			ExpPanic: testhelper.MkExpPanic(
+				`coloursetter.Theme.RequireContrast: unknown role: "bg"`),
+			f: func(th *Theme) { th.RequireContrast("fg", "bg", WCAG2, 4.5) },
+		},
+		{
+			ID: testhelper.MkID("bad minimum contrast"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.Theme.RequireContrast:" +
+					" the minimum contrast (0) must be greater than zero"),
+			f: func(th *Theme) { th.RequireContrast("fg", "fg", WCAG2, 0) },
+		},
+		{
+			ID: testhelper.MkID("too few roles"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.Theme.RequireDistinct:" +
+					" at least 2 roles are needed, 1 given"),
+			f: func(th *Theme) { th.RequireDistinct(5, "fg") },
+		},
+		{
+			ID: testhelper.MkID("bad minimum Delta E"),
+			ExpPanic: testhelper.MkExpPanic(
+				"coloursetter.Theme.RequireCVDDistinct:" +
+					" the minimum Delta E (-1) must be greater than zero"),
+			f: func(th *Theme) { th.RequireCVDDistinct(-1, "fg", "fg") },
+		},
+	}
+
+	for _, tc := range testCases {
+		th := NewTheme().AddRole("fg", &c)
+
+		panicked, panicVal := testhelper.PanicSafe(func() { tc.f(th) })
+		testhelper.CheckExpPanic(t, panicked, panicVal, tc)
+	}
+}
@@ -0,0 +1,387 @@
+package coloursetter
+
+import (
+	"errors"
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// These are the operators which may be used in a ColourTweak
+const (
+	TweakSet = '='
+	TweakAdd = '+'
+	TweakSub = '-'
+	TweakMul = '*'
+
+	tweakOps = "=+-*"
+)
+
+// tweakComponent describes a component of a colour that can be tweaked
+type tweakComponent struct {
+	name  string
+	alias string
+	// maxVal is the largest value of the component; the smallest is zero.
+	// A percentage is taken as a proportion of this value.
+	maxVal float64
+	// wraps is set if the component is an angle, values outside the range
+	// are wrapped around rather than clamped
+	wraps bool
+	get   func(c color.RGBA) float64               //nolint:misspell
+	set   func(c color.RGBA, v float64) color.RGBA //nolint:misspell
+}
+
+// hslComponentSetter returns a function which sets the given HSL component
+// (0: hue, 1: saturation, 2: lightness) of a colour
+func hslComponentSetter(idx int) func(color.RGBA, float64) color.RGBA { //nolint:misspell
+	return func(c color.RGBA, v float64) color.RGBA { //nolint:misspell
+		hsl := rgbToHSL(c)
+		hsl[idx] = v
+
+		return hslToRGB(hsl, c.A)
+	}
+}
+
+// tweakComponents lists the colour components that can be tweaked
+var tweakComponents = []tweakComponent{
+	{
+		name: "red", alias: "r", maxVal: math.MaxUint8,
+		get: func(c color.RGBA) float64 { return float64(c.R) }, //nolint:misspell
+		set: func(c color.RGBA, v float64) color.RGBA { //nolint:misspell
+			c.R = clampUint8(v)
+			return c
+		},
+	},
+	{
+		name: "green", alias: "g", maxVal: math.MaxUint8,
+		get: func(c color.RGBA) float64 { return float64(c.G) }, //nolint:misspell
+		set: func(c color.RGBA, v float64) color.RGBA { //nolint:misspell
+			c.G = clampUint8(v)
+			return c
+		},
+	},
+	{
+		name: "blue", alias: "b", maxVal: math.MaxUint8,
+		get: func(c color.RGBA) float64 { return float64(c.B) }, //nolint:misspell
+		set: func(c color.RGBA, v float64) color.RGBA { //nolint:misspell
+			c.B = clampUint8(v)
+			return c
+		},
+	},
+	{
+		name: "alpha", alias: "a", maxVal: math.MaxUint8,
+		get: func(c color.RGBA) float64 { return float64(c.A) }, //nolint:misspell
+		set: func(c color.RGBA, v float64) color.RGBA { //nolint:misspell
+			c.A = clampUint8(v)
+			return c
+		},
+	},
+	{
+		name: "hue", alias: "h", maxVal: 360, wraps: true, //nolint:mnd
+		get: func(c color.RGBA) float64 { return rgbToHSL(c)[0] }, //nolint:misspell
+		set: hslComponentSetter(0),
+	},
+	{
+		name: "saturation", alias: "s", maxVal: 100, //nolint:mnd
+		get: func(c color.RGBA) float64 { return rgbToHSL(c)[1] }, //nolint:misspell
+		set: hslComponentSetter(1),
+	},
+	{
+		name: "lightness", alias: "l", maxVal: 100, //nolint:mnd
+		get: func(c color.RGBA) float64 { return rgbToHSL(c)[2] }, //nolint:misspell
+		set: hslComponentSetter(2),
+	},
+}
+
+// rgbToHSL converts the colour to hue (in degrees, 0 to 360), saturation
+// and lightness (both 0 to 100). The alpha channel is ignored.
+func rgbToHSL(c color.RGBA) [3]float64 { //nolint:misspell
+	r := float64(c.R) / math.MaxUint8
+	g := float64(c.G) / math.MaxUint8
+	b := float64(c.B) / math.MaxUint8
+
+	hi, lo := max(r, g, b), min(r, g, b)
+	l := (hi + lo) / 2 //nolint:mnd
+
+	if hi == lo {
+		return [3]float64{0, 0, l * 100} //nolint:mnd
+	}
+
+	d := hi - lo
+
+	s := d / (1 - math.Abs(2*l-1)) //nolint:mnd
+
+	var h float64
+
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d+6, 6) //nolint:mnd
+	case g:
+		h = (b-r)/d + 2 //nolint:mnd
+	default:
+		h = (r-g)/d + 4 //nolint:mnd
+	}
+
+	return [3]float64{h * 60, s * 100, l * 100} //nolint:mnd
+}
+
+// hslToRGB converts the hue (in degrees), saturation and lightness (both 0
+// to 100) to an RGBA colour with the given alpha
+//
+//nolint:mnd
+func hslToRGB(hsl [3]float64, alpha uint8) color.RGBA { //nolint:misspell
+	h := math.Mod(hsl[0], 360) / 60
+	s := max(0, min(100, hsl[1])) / 100
+	l := max(0, min(100, hsl[2])) / 100
+
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	m := l - chroma/2
+
+	var r, g, b float64
+
+	switch {
+	case h < 1:
+		r, g = chroma, x
+	case h < 2:
+		r, g = x, chroma
+	case h < 3:
+		g, b = chroma, x
+	case h < 4:
+		g, b = x, chroma
+	case h < 5:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+
+	return color.RGBA{ //nolint:misspell
+		R: clampUint8((r + m) * math.MaxUint8),
+		G: clampUint8((g + m) * math.MaxUint8),
+		B: clampUint8((b + m) * math.MaxUint8),
+		A: alpha,
+	}
+}
+
+// findTweakComponent returns the component with the given name or alias
+func findTweakComponent(name string) (tweakComponent, bool) {
+	lc := strings.ToLower(strings.TrimSpace(name))
+
+	for _, tc := range tweakComponents {
+		if lc == tc.name || lc == tc.alias {
+			return tc, true
+		}
+	}
+
+	return tweakComponent{}, false
+}
+
+// tweakComponentNames returns the names of the tweakable components
+func tweakComponentNames() string {
+	names := make([]string, 0, len(tweakComponents))
+	for _, tc := range tweakComponents {
+		names = append(names, tc.name+" ("+tc.alias+")")
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// ColourTweak records an adjustment to one component of a colour: red,
+// green, blue, alpha, hue, saturation or lightness. The Op is one of
+// TweakSet, TweakAdd, TweakSub or TweakMul. If Percent is set then the
+// Val (other than for TweakMul) is taken as a percentage of the full range
+// of the component; for TweakMul it is divided by 100.
+type ColourTweak struct {
+	Component string
+	Op        byte
+	Val       float64
+	Percent   bool
+}
+
+// ParseColourTweak parses a single adjustment: a component name followed by
+// an operator and a value (for instance, "lightness+10%" or "alpha=128")
+func ParseColourTweak(s string) (ColourTweak, error) {
+	trimmed := strings.TrimSpace(s)
+
+	opIdx := strings.IndexAny(trimmed, tweakOps)
+	if opIdx < 0 {
+		return ColourTweak{},
+			fmt.Errorf("bad adjustment %q: no operator (one of %q) found",
+				s, tweakOps)
+	}
+
+	comp, ok := findTweakComponent(trimmed[:opIdx])
+	if !ok {
+		return ColourTweak{},
+			fmt.Errorf("bad adjustment %q: unknown component %q"+
+				" (it must be one of: %s)",
+				s, strings.TrimSpace(trimmed[:opIdx]), tweakComponentNames())
+	}
+
+	t := ColourTweak{Component: comp.name, Op: trimmed[opIdx]}
+
+	valStr := strings.TrimSpace(trimmed[opIdx+1:])
+	if pctStr, found := strings.CutSuffix(valStr, "%"); found {
+		t.Percent = true
+		valStr = strings.TrimSpace(pctStr)
+	}
+
+	v, err := strconv.ParseFloat(valStr, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+		return ColourTweak{},
+			fmt.Errorf("bad adjustment %q: the value (%q)"+
+				" must be a non-negative number", s, valStr)
+	}
+
+	t.Val = v
+
+	return t, nil
+}
+
+// Apply returns the colour with the adjustment applied. The result is
+// clamped to the range of the component, except for the hue which wraps
+// around.
+func (t ColourTweak) Apply(c color.RGBA) color.RGBA { //nolint:misspell
+	comp, ok := findTweakComponent(t.Component)
+	if !ok {
+		return c
+	}
+
+	v := t.Val
+	if t.Percent {
+		if t.Op == TweakMul {
+			v /= 100 //nolint:mnd
+		} else {
+			v = v * comp.maxVal / 100 //nolint:mnd
+		}
+	}
+
+	cur := comp.get(c)
+
+	switch t.Op {
+	case TweakSet:
+		cur = v
+	case TweakAdd:
+		cur += v
+	case TweakSub:
+		cur -= v
+	case TweakMul:
+		cur *= v
+	default:
+		return c
+	}
+
+	if comp.wraps {
+		cur = math.Mod(math.Mod(cur, comp.maxVal)+comp.maxVal, comp.maxVal)
+	} else {
+		cur = max(0, min(comp.maxVal, cur))
+	}
+
+	return comp.set(c, cur)
+}
+
+// String returns the adjustment in the form in which it is parsed
+func (t ColourTweak) String() string {
+	s := t.Component + string(t.Op) + strconv.FormatFloat(t.Val, 'g', -1, 64)
+	if t.Percent {
+		s += "%"
+	}
+
+	return s
+}
+
+// Tweak is used to adjust components of an existing colour. The value is
+// a list of adjustments, each of which is a component (red, green, blue,
+// alpha, hue, saturation or lightness), an operator and a value. The
+// operator is one of:
+//
+//	=  set the component to the value
+//	+  add the value to the component
+//	-  subtract the value from the component
+//	*  multiply the component by the value
+//
+// A value followed by % is taken as a percentage of the full range of the
+// component (or, with *, as a percentage of the current value). The
+// adjustments are applied in order (for instance, "lightness+10%,alpha=50%").
+//
+//nolint:misspell
+type Tweak struct {
+	psetter.ValueReqMandatory
+
+	Value *color.RGBA
+	// The StrListSeparator allows you to override the default separator
+	// between list elements.
+	psetter.StrListSeparator
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// adjustments and, if they are all valid, applies them to the Value.
+func (s Tweak) SetWithVal(_ string, paramVal string) error {
+	tweaks := []ColourTweak{}
+	errs := []error{}
+
+	for _, part := range strings.Split(paramVal, s.GetSeparator()) {
+		t, err := ParseColourTweak(part)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		tweaks = append(tweaks, t)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	c := *s.Value
+	for _, t := range tweaks {
+		c = t.Apply(c)
+	}
+
+	*s.Value = c
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Tweak) AllowedValues() string {
+	return s.ListValDesc("adjustments") +
+		", applied in order. Each adjustment is a colour component," +
+		" an operator and a non-negative number." +
+		" The component is one of: " + tweakComponentNames() + "." +
+		" The red, green, blue and alpha values range from 0 to 255," +
+		" the hue (which wraps around) from 0 to 360" +
+		" and the saturation and lightness from 0 to 100." +
+		" The operator is one of: " + string(TweakSet) + " (set)," +
+		" " + string(TweakAdd) + " (add)," +
+		" " + string(TweakSub) + " (subtract)" +
+		" or " + string(TweakMul) + " (multiply)." +
+		" A number followed by % is a percentage of the range" +
+		" of the component (or, when multiplying, of the current value)"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s Tweak) ValDescribe() string {
+	return "adjustments"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Tweak) CurrentValue() string {
+	return ChannelOrderRGBA.Hex(*s.Value)
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil.
+func (s Tweak) CheckSetter(name string) {
+	intro := name + ": coloursetter.Tweak Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " the Value to be set is nil")
+	}
+}
@@ -0,0 +1,129 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestTweakCheck(t *testing.T) {
+	c := color.RGBA{} //nolint:misspell
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		v Tweak
+	}{
+		{
+			ID: testhelper.MkID("No panic expected"),
+			v:  Tweak{Value: &c},
+		},
+		{
+			ID: testhelper.MkID("Panic expected, nil Value"),
+			ExpPanic: testhelper.MkExpPanic(
+				"test-param: coloursetter.Tweak Check failed:" +
+					" the Value to be set is nil"),
+			v: Tweak{},
+		},
+	}
+
+	for _, tc := range testCases {
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			tc.v.CheckSetter("test-param")
+		})
+		testhelper.CheckExpPanic(t, panicked, panicVal, tc)
+	}
+}
+
+func TestTweakSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("lightness and alpha"),
+			v:      "lightness+10%,alpha=50%",
+			expVal: "#4080bf80",
+		},
+		{
+			ID:     testhelper.MkID("set red, by alias"),
+			v:      "r=128",
+			expVal: "#806699ff",
+		},
+		{
+			ID:     testhelper.MkID("multiply and add a percentage"),
+			v:      "red*0.5, green+20%",
+			expVal: "#1a9999ff",
+		},
+		{
+			ID:     testhelper.MkID("rotate the hue"),
+			v:      "hue+180",
+			expVal: "#996633ff",
+		},
+		{
+			ID:     testhelper.MkID("hue wraps"),
+			v:      "h-390",
+			expVal: "#339999ff",
+		},
+		{
+			ID:     testhelper.MkID("halve the saturation"),
+			v:      "s*50%",
+			expVal: "#4d6680ff",
+		},
+		{
+			ID:     testhelper.MkID("clamped"),
+			v:      "alpha*2,blue+200",
+			expVal: "#3366ffff",
+		},
+		{
+			ID:     testhelper.MkID("applied in order"),
+			v:      "lightness=100,red-10%",
+			expVal: "#e6ffffff",
+		},
+		{
+			ID: testhelper.MkID("unknown component"),
+			ExpErr: testhelper.MkExpErr(`bad adjustment "foo=1":` +
+				` unknown component "foo" (it must be one of:` +
+				" red (r), green (g), blue (b), alpha (a)," +
+				" hue (h), saturation (s), lightness (l))"),
+			v:      "foo=1",
+			expVal: "#336699ff",
+		},
+		{
+			ID: testhelper.MkID("several errors, nothing applied"),
+			ExpErr: testhelper.MkExpErr(
+				`bad adjustment "red+-5": the value ("-5")`+
+					` must be a non-negative number`,
+				`bad adjustment "blue": no operator (one of "=+-*") found`),
+			v:      "green=0,red+-5,blue",
+			expVal: "#336699ff",
+		},
+	}
+
+	for _, tc := range testCases {
+		c := color.RGBA{R: 0x33, G: 0x66, B: 0x99, A: 0xff} //nolint:misspell
+
+		err := Tweak{Value: &c}.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+		testhelper.DiffString(t, tc.IDStr(), "value",
+			ChannelOrderRGBA.Hex(c), tc.expVal)
+	}
+}
+
+func TestHSLRoundTrip(t *testing.T) {
+	for _, c := range []color.RGBA{ //nolint:misspell
+		{R: 0x33, G: 0x66, B: 0x99, A: 0xff},
+		{R: 0xff, A: 0x80},
+		{G: 0xff, B: 0x0a},
+		{R: 1, G: 2, B: 3, A: 4},
+		{R: 200, G: 200, B: 200, A: 0xff},
+	} {
+		if got := hslToRGB(rgbToHSL(c), c.A); got != c {
+			t.Errorf("HSL round trip: %s became %s",
+				ChannelOrderRGBA.Hex(c), ChannelOrderRGBA.Hex(got))
+		}
+	}
+}
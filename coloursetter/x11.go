@@ -0,0 +1,111 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	x11RGBPrefix  = "rgb:"
+	x11RGBIPrefix = "rgbi:"
+
+	x11MaxDigits = 4
+)
+
+// x11Channels returns the three channel values from an X11 colour
+// specification (the part after the prefix). They are separated by
+// slashes.
+func x11Channels(s, prefix string) ([]string, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 { //nolint:mnd
+		return nil,
+			fmt.Errorf("bad X11 colour %q: 3 parts separated by '/' expected,"+
+				" %d found",
+				prefix+s, len(parts))
+	}
+
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+
+	return parts, nil
+}
+
+// parseX11RGB parses the value of an X11 "rgb:" colour specification as
+// accepted by XParseColor, for instance "ffff/8080/0000". Each channel has
+// from 1 to 4 hexadecimal digits which are scaled to the 8-bit range so
+// that "f", "ff", "fff" and "ffff" all give 255.
+func parseX11RGB(s string) (color.RGBA, error) { //nolint:misspell
+	parts, err := x11Channels(s, x11RGBPrefix)
+	if err != nil {
+		return color.RGBA{}, err //nolint:misspell
+	}
+
+	vals := make([]uint8, 0, len(parts))
+
+	for i, p := range parts {
+		if len(p) == 0 || len(p) > x11MaxDigits {
+			return color.RGBA{}, //nolint:misspell
+				fmt.Errorf("bad X11 colour %q:"+
+					" part %d (%q) must have from 1 to %d hex digits",
+					x11RGBPrefix+s, i+1, p, x11MaxDigits)
+		}
+
+		v, err := strconv.ParseUint(p, 16, 16)
+		if err != nil {
+			return color.RGBA{}, //nolint:misspell
+				fmt.Errorf("bad X11 colour %q:"+
+					" part %d (%q) is not a hexadecimal number",
+					x11RGBPrefix+s, i+1, p)
+		}
+
+		maxVal := float64(uint64(1)<<(4*len(p)) - 1) //nolint:mnd
+		vals = append(vals,
+			uint8(math.Round(float64(v)*math.MaxUint8/maxVal)))
+	}
+
+	return setChannels(string(ChannelOrderRGB), vals), nil
+}
+
+// parseX11RGBI parses the value of an X11 "rgbi:" colour specification as
+// accepted by XParseColor, for instance "1.0/0.5/0.0". Each channel is a
+// floating point intensity in the range 0.0 to 1.0.
+func parseX11RGBI(s string) (color.RGBA, error) { //nolint:misspell
+	parts, err := x11Channels(s, x11RGBIPrefix)
+	if err != nil {
+		return color.RGBA{}, err //nolint:misspell
+	}
+
+	vals := make([]uint8, 0, len(parts))
+
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return color.RGBA{}, //nolint:misspell
+				fmt.Errorf("bad X11 colour %q:"+
+					" part %d (%q) is not a number",
+					x11RGBIPrefix+s, i+1, p)
+		}
+
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return color.RGBA{}, //nolint:misspell
+				fmt.Errorf("bad X11 colour %q:"+
+					" part %d (%q) is not a finite number",
+					x11RGBIPrefix+s, i+1, p)
+		}
+
+		if v < 0 || v > 1 {
+			return color.RGBA{}, //nolint:misspell
+				fmt.Errorf("bad X11 colour %q:"+
+					" part %d (%q) must be in the range 0.0 to 1.0",
+					x11RGBIPrefix+s, i+1, p)
+		}
+
+		vals = append(vals, uint8(math.Round(v*math.MaxUint8)))
+	}
+
+	return setChannels(string(ChannelOrderRGB), vals), nil
+}
@@ -0,0 +1,84 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colourtesthelper"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestParseX11(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v      string
+		expVal color.RGBA //nolint:misspell
+	}{
+		{
+			ID:     testhelper.MkID("rgb: 4 digits"),
+			v:      "rgb:ffff/8080/0000",
+			expVal: color.RGBA{R: 0xff, G: 0x80, B: 0, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("rgb: mixed digits"),
+			v:      "RGB:f/80/000",
+			expVal: color.RGBA{R: 0xff, G: 0x80, B: 0, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("rgb: 3 digits"),
+			v:      "rgb:800/fff/123",
+			expVal: color.RGBA{R: 0x80, G: 0xff, B: 0x12, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("rgbi:"),
+			v:      "rgbi:1.0/0.5/0",
+			expVal: color.RGBA{R: 0xff, G: 0x80, B: 0, A: 0xff}, //nolint:misspell
+		},
+		{
+			ID: testhelper.MkID("rgb: too many digits"),
+			ExpErr: testhelper.MkExpErr(`bad X11 colour "rgb:fffff/0/0":` +
+				` part 1 ("fffff") must have from 1 to 4 hex digits`),
+			v: "rgb:fffff/0/0",
+		},
+		{
+			ID: testhelper.MkID("rgb: missing part"),
+			ExpErr: testhelper.MkExpErr(`bad X11 colour "rgb:ff/00":` +
+				` 3 parts separated by '/' expected, 2 found`),
+			v: "rgb:ff/00",
+		},
+		{
+			ID: testhelper.MkID("rgb: bad digits"),
+			ExpErr: testhelper.MkExpErr(`bad X11 colour "rgb:ff/0g/00":` +
+				` part 2 ("0g") is not a hexadecimal number`),
+			v: "rgb:ff/0g/00",
+		},
+		{
+			ID: testhelper.MkID("rgbi: out of range"),
+			ExpErr: testhelper.MkExpErr(`bad X11 colour "rgbi:1.5/0/0":` +
+				` part 1 ("1.5") must be in the range 0.0 to 1.0`),
+			v: "rgbi:1.5/0/0",
+		},
+		{
+			ID: testhelper.MkID("rgbi: NaN"),
+			ExpErr: testhelper.MkExpErr(`bad X11 colour "rgbi:0/NaN/0":` +
+				` part 2 ("NaN") is not a finite number`),
+			v: "rgbi:0/NaN/0",
+		},
+		{
+			ID: testhelper.MkID("rgbi: Inf"),
+			ExpErr: testhelper.MkExpErr(`bad X11 colour "rgbi:0/0/-Inf":` +
+				` part 3 ("-Inf") is not a finite number`),
+			v: "rgbi:0/0/-Inf",
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := colourParser{}.parseRGBA(tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			colourtesthelper.DiffRGBA(t, tc.IDStr(), "colour", c, tc.expVal)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package coloursetter
+
+import (
+	"fmt"
+	"image/color" //nolint:misspell
+	"math"
+	"strings"
+
+	"github.com/nickwells/colour.mod/v2/colour"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// LumaCoefficients identifies the set of weights used to calculate the
+// luma (brightness) of a colour from its red, green and blue channels
+type LumaCoefficients int
+
+// These are the supported luma coefficients. Both are used with the full
+// (0-255) range of values.
+const (
+	// BT601 uses the ITU-R BT.601 weights (0.299, 0.587, 0.114). These are
+	// the weights used by the standard library's color.YCbCr and
+	// color.Gray conversions.
+	BT601 LumaCoefficients = iota
+	// BT709 uses the ITU-R BT.709 weights (0.2126, 0.7152, 0.0722) as used
+	// for HD video
+	BT709
+)
+
+const ycbcrPrefix = "ycbcr("
+
+// String returns the name of the coefficients
+func (lc LumaCoefficients) String() string {
+	switch lc {
+	case BT601:
+		return "BT.601"
+	case BT709:
+		return "BT.709"
+	}
+
+	return fmt.Sprintf("LumaCoefficients(%d)", int(lc))
+}
+
+// Check returns a non-nil error if the LumaCoefficients is not one of the
+// supported values.
+func (lc LumaCoefficients) Check() error {
+	if lc < BT601 || lc > BT709 {
+		return fmt.Errorf("%d is not a valid LumaCoefficients", int(lc))
+	}
+
+	return nil
+}
+
+// weights returns the red and blue weights, the green weight is 1 minus
+// the sum of these
+func (lc LumaCoefficients) weights() (float64, float64) {
+	if lc == BT709 {
+		return 0.2126, 0.0722 //nolint:mnd
+	}
+
+	return 0.299, 0.114 //nolint:mnd
+}
+
+// clampUint8 rounds the value and clamps it to the range of a uint8
+func clampUint8(v float64) uint8 {
+	return uint8(math.Round(max(0, min(math.MaxUint8, v))))
+}
+
+// luma returns the luma of the colour as a value in the range 0.0 to 255.0
+func (lc LumaCoefficients) luma(c color.RGBA) float64 { //nolint:misspell
+	kr, kb := lc.weights()
+
+	return kr*float64(c.R) + (1-kr-kb)*float64(c.G) + kb*float64(c.B)
+}
+
+// ToYCbCr converts the colour into a full-range Y'CbCr value using the
+// coefficients. The alpha channel is ignored.
+func (lc LumaCoefficients) ToYCbCr(c color.RGBA) color.YCbCr { //nolint:misspell
+	if lc == BT601 {
+		y, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B) //nolint:misspell
+
+		return color.YCbCr{Y: y, Cb: cb, Cr: cr} //nolint:misspell
+	}
+
+	const mid = 128
+
+	kr, kb := lc.weights()
+	y := lc.luma(c)
+
+	return color.YCbCr{ //nolint:misspell
+		Y:  clampUint8(y),
+		Cb: clampUint8(mid + (float64(c.B)-y)/(2*(1-kb))), //nolint:mnd
+		Cr: clampUint8(mid + (float64(c.R)-y)/(2*(1-kr))), //nolint:mnd
+	}
+}
+
+// FromYCbCr converts the full-range Y'CbCr value into a fully opaque colour
+// using the coefficients.
+func (lc LumaCoefficients) FromYCbCr(v color.YCbCr) color.RGBA { //nolint:misspell
+	if lc == BT601 {
+		r, g, b := color.YCbCrToRGB(v.Y, v.Cb, v.Cr) //nolint:misspell
+
+		return color.RGBA{R: r, G: g, B: b, A: math.MaxUint8} //nolint:misspell
+	}
+
+	const mid = 128
+
+	kr, kb := lc.weights()
+	kg := 1 - kr - kb
+	y := float64(v.Y)
+	r := y + 2*(1-kr)*(float64(v.Cr)-mid) //nolint:mnd
+	b := y + 2*(1-kb)*(float64(v.Cb)-mid) //nolint:mnd
+	g := (y - kr*r - kb*b) / kg
+
+	return color.RGBA{ //nolint:misspell
+		R: clampUint8(r),
+		G: clampUint8(g),
+		B: clampUint8(b),
+		A: math.MaxUint8,
+	}
+}
+
+// parseYCbCrParts parses the part of a "ycbcr(y, cb, cr)" value following
+// the prefix (including the closing bracket).
+func parseYCbCrParts(s string) (color.YCbCr, error) { //nolint:misspell
+	var v color.YCbCr //nolint:misspell
+
+	body, ok := strings.CutSuffix(strings.TrimSpace(s), ")")
+	if !ok {
+		return v, fmt.Errorf("bad ycbcr colour %q: no trailing %q",
+			ycbcrPrefix+s, ")")
+	}
+
+	parts := strings.Split(body, ",")
+	if len(parts) != 3 { //nolint:mnd
+		return v, fmt.Errorf("bad ycbcr colour %q: 3 values expected,"+
+			" %d found",
+			ycbcrPrefix+s, len(parts))
+	}
+
+	names := []string{"Y", "Cb", "Cr"}
+	vals := make([]uint8, 0, len(parts))
+
+	for i, p := range parts {
+		val, err := colour.ParseColourPart(strings.TrimSpace(p), names[i])
+		if err != nil {
+			return v, err
+		}
+
+		vals = append(vals, val)
+	}
+
+	return color.YCbCr{Y: vals[0], Cb: vals[1], Cr: vals[2]}, nil //nolint:misspell
+}
+
+// parseYCbCr parses the part of a "ycbcr(y, cb, cr)" value following the
+// prefix and converts it into a colour using the coefficients.
+func parseYCbCr(s string, lc LumaCoefficients) (color.RGBA, error) { //nolint:misspell
+	v, err := parseYCbCrParts(s)
+	if err != nil {
+		return color.RGBA{}, err //nolint:misspell
+	}
+
+	return lc.FromYCbCr(v), nil
+}
+
+// YCbCr is used to set a standard library color.YCbCr value. Any colour
+// value accepted by the RGB setter may be given and is converted using the
+// Coefficients. A value given as ycbcr(y, cb, cr) is stored as given.
+//
+//nolint:misspell
+type YCbCr struct {
+	psetter.ValueReqMandatory
+
+	Value    *color.YCbCr
+	Families colour.Families
+
+	// Coefficients gives the luma coefficients used to convert colours
+	// into Y'CbCr values. If it is not set the BT.601 coefficients are
+	// used.
+	Coefficients LumaCoefficients
+	// ChannelOrder gives the order of the channels in hexadecimal and
+	// integer colour values. If it is not set the conventional order
+	// (RGBA) is used.
+	ChannelOrder ChannelOrder
+}
+
+// parser returns the colourParser for this setter
+func (s YCbCr) parser() colourParser {
+	return colourParser{
+		families:     s.Families,
+		channelOrder: s.ChannelOrder,
+		coefficients: s.Coefficients,
+	}
+}
+
+// SetWithVal (called with the value following the parameter) parses the
+// value and sets the Value to the Y'CbCr equivalent.
+func (s YCbCr) SetWithVal(_ string, paramVal string) error {
+	trimmed := strings.TrimSpace(paramVal)
+	if strings.HasPrefix(strings.ToLower(trimmed), ycbcrPrefix) {
+		v, err := parseYCbCrParts(trimmed[len(ycbcrPrefix):])
+		if err == nil {
+			*s.Value = v
+		}
+
+		return err
+	}
+
+	c, err := s.parser().parseRGBA(paramVal)
+	if err != nil {
+		return err
+	}
+
+	*s.Value = s.Coefficients.ToYCbCr(c)
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s YCbCr) AllowedValues() string {
+	return s.parser().allowedValues() +
+		"\n\n" +
+		"The colour is converted to Y'CbCr using the " +
+		s.Coefficients.String() + " coefficients" +
+		" except for values given as ycbcr(...) which are used as given"
+}
+
+// ValDescribe returns a string describing the value that can follow the
+// parameter
+func (s YCbCr) ValDescribe() string {
+	return "colour"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s YCbCr) CurrentValue() string {
+	return fmt.Sprintf("ycbcr(%d, %d, %d)", s.Value.Y, s.Value.Cb, s.Value.Cr)
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or the Families value is incorrect or the Coefficients or
+// ChannelOrder is invalid.
+func (s YCbCr) CheckSetter(name string) {
+	intro := name + ": coloursetter.YCbCr Check failed:"
+
+	if s.Value == nil {
+		panic(intro + " YCbCr.Value: is nil")
+	}
+
+	if err := s.Families.Check(); err != nil {
+		panic(intro + " YCbCr.Families: " + err.Error())
+	}
+
+	if err := s.Coefficients.Check(); err != nil {
+		panic(intro + " YCbCr.Coefficients: " + err.Error())
+	}
+
+	if err := s.ChannelOrder.Check(); err != nil {
+		panic(intro + " YCbCr.ChannelOrder: " + err.Error())
+	}
+}
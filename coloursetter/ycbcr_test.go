@@ -0,0 +1,185 @@
+package coloursetter
+
+import (
+	"image/color" //nolint:misspell
+	"strings"
+	"testing"
+
+	"github.com/nickwells/colour.mod/v2/colourtesthelper"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestYCbCrSetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      YCbCr
+		v      string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("BT.601 - red"),
+			v:      "#ff0000",
+			expVal: "ycbcr(76, 85, 255)",
+		},
+		{
+			ID:     testhelper.MkID("BT.709 - red"),
+			s:      YCbCr{Coefficients: BT709},
+			v:      "#ff0000",
+			expVal: "ycbcr(54, 99, 255)",
+		},
+		{
+			ID:     testhelper.MkID("BT.709 - white"),
+			s:      YCbCr{Coefficients: BT709},
+			v:      "white",
+			expVal: "ycbcr(255, 128, 128)",
+		},
+		{
+			ID:     testhelper.MkID("ycbcr - stored as given"),
+			s:      YCbCr{Coefficients: BT709},
+			v:      "YCbCr(16, 0x80, 240)",
+			expVal: "ycbcr(16, 128, 240)",
+		},
+		{
+			ID: testhelper.MkID("ycbcr - too few values"),
+			ExpErr: testhelper.MkExpErr(`bad ycbcr colour "ycbcr(16, 128)":` +
+				` 3 values expected, 2 found`),
+			v: "ycbcr(16, 128)",
+		},
+		{
+			ID: testhelper.MkID("ycbcr - missing bracket"),
+			ExpErr: testhelper.MkExpErr(`bad ycbcr colour "ycbcr(16, 128, 1":` +
+				` no trailing ")"`),
+			v: "ycbcr(16, 128, 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		var v color.YCbCr //nolint:misspell
+
+		s := tc.s
+		s.Value = &v
+		err := s.SetWithVal("", tc.v)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "CurrentValue",
+				s.CurrentValue(), tc.expVal)
+		}
+	}
+}
+
+func TestYCbCrRoundTrip(t *testing.T) {
+	colours := []color.RGBA{ //nolint:misspell
+		{R: 0xff, A: 0xff},
+		{G: 0xff, A: 0xff},
+		{B: 0xff, A: 0xff},
+		{R: 0x12, G: 0x34, B: 0x56, A: 0xff},
+	}
+
+	for _, lc := range []LumaCoefficients{BT601, BT709} {
+		for _, c := range colours {
+			rt := lc.FromYCbCr(lc.ToYCbCr(c))
+			for _, d := range []int{
+				int(rt.R) - int(c.R),
+				int(rt.G) - int(c.G),
+				int(rt.B) - int(c.B),
+			} {
+				if d < -2 || d > 2 {
+					t.Errorf("%s: %v round-trips to %v", lc, c, rt)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestParseYCbCrCoefficients(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		lc     LumaCoefficients
+		expVal color.RGBA //nolint:misspell
+	}{
+		{
+			ID:     testhelper.MkID("BT.601"),
+			lc:     BT601,
+			expVal: BT601.FromYCbCr(color.YCbCr{Y: 54, Cb: 99, Cr: 255}), //nolint:misspell
+		},
+		{
+			ID:     testhelper.MkID("BT.709"),
+			lc:     BT709,
+			expVal: color.RGBA{R: 0xfe, A: 0xff}, //nolint:misspell
+		},
+	}
+
+	for _, tc := range testCases {
+		p := colourParser{coefficients: tc.lc}
+
+		c, err := p.parseRGBA("ycbcr(54, 99, 255)")
+		testhelper.CheckExpErr(t, err, tc)
+
+		colourtesthelper.DiffRGBA(t, tc.IDStr(), "colour", c, tc.expVal)
+
+		if !strings.Contains(p.allowedValues(),
+			"converted using the "+tc.lc.String()+" coefficients") {
+			t.Log(tc.IDStr())
+			t.Error("\tthe allowed values should name the coefficients")
+		}
+	}
+}
+
+func TestGraySetWithVal(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		luma     LumaCoefficients
+		v        string
+		expVal   string
+		expVal16 string
+	}{
+		{
+			ID:       testhelper.MkID("BT.601 - green"),
+			v:        "#00ff00",
+			expVal:   "Gray{Y: 0x96}",
+			expVal16: "Gray16{Y: 0x9645}",
+		},
+		{
+			ID:       testhelper.MkID("BT.709 - green"),
+			luma:     BT709,
+			v:        "#00ff00",
+			expVal:   "Gray{Y: 0xb6}",
+			expVal16: "Gray16{Y: 0xb717}",
+		},
+		{
+			ID:       testhelper.MkID("white"),
+			v:        "white",
+			expVal:   "Gray{Y: 0xff}",
+			expVal16: "Gray16{Y: 0xffff}",
+		},
+	}
+
+	for _, tc := range testCases {
+		var (
+			g   color.Gray   //nolint:misspell
+			g16 color.Gray16 //nolint:misspell
+		)
+
+		s := Gray{Value: &g, Luma: tc.luma}
+		if err := s.SetWithVal("", tc.v); err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: unexpected error: %s", err)
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "Gray.CurrentValue",
+			s.CurrentValue(), tc.expVal)
+
+		s16 := Gray16{Value: &g16, Luma: tc.luma}
+		if err := s16.SetWithVal("", tc.v); err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: unexpected error: %s", err)
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "Gray16.CurrentValue",
+			s16.CurrentValue(), tc.expVal16)
+	}
+}
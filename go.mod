@@ -6,6 +6,7 @@ require (
 	github.com/nickwells/colour.mod/v2 v2.4.1
 	github.com/nickwells/param.mod/v7 v7.1.2
 	github.com/nickwells/testhelper.mod/v2 v2.5.0
+	golang.org/x/term v0.41.0
 )
 
 require github.com/nickwells/english.mod v1.2.8 // indirect
@@ -21,4 +22,5 @@ require (
 	github.com/nickwells/tempus.mod v1.2.10 // indirect
 	github.com/nickwells/twrap.mod v1.5.13 // indirect
 	golang.org/x/exp v0.0.0-20260312153236-7ab1446f8b90 // indirect
+	golang.org/x/sys v0.42.0 // indirect
 )